@@ -2,11 +2,13 @@ package main
 
 import (
     "log"
+    _ "time/tzdata" // the school time zone must resolve in minimal containers
 
     "github.com/in-nis/cnis-back/internal/api"
     "github.com/in-nis/cnis-back/internal/config"
     "github.com/in-nis/cnis-back/internal/db"
     "github.com/in-nis/cnis-back/internal/cron"
+    "github.com/in-nis/cnis-back/internal/excel"
     "github.com/joho/godotenv"
 )
 
@@ -19,10 +21,29 @@ func main() {
 
 	db.InitDB(cfg.DBUrl)
 
-    r := api.SetupRouter(cfg)
+    downloader := excel.NewDownloader()
+    downloader.Timeout = cfg.DownloadTimeout
+    downloader.Retries = cfg.DownloadRetries
+    downloader.MaxBytes = cfg.DownloadMaxBytes
+
+    source, err := excel.NewSource(cfg.ScheduleSource, downloader)
+    if err != nil {
+        log.Fatalf("invalid schedule source %q: %v", cfg.ScheduleSource, err)
+    }
+    log.Println("📚 Schedule source:", source)
+
+    layout, err := excel.LoadLayout(cfg.ScheduleLayout)
+    if err != nil {
+        log.Fatalf("failed to load schedule layout: %v", err)
+    }
+    parser := excel.NewParser(layout)
+    parser.Strict = cfg.ScheduleStrict
+    importer := excel.NewService(source, parser)
+
+    r := api.SetupRouter(cfg, importer)
 
     // Start cron jobs
-    cron.StartJobs()
+    cron.StartJobs(importer)
 
     log.Println("Server running on :8080")
     r.Run(":8000")
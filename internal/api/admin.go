@@ -0,0 +1,252 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/in-nis/cnis-back/internal/db"
+	"github.com/in-nis/cnis-back/internal/excel"
+)
+
+// ListGenerations godoc
+// @Summary      List schedule generations
+// @Description  Returns imported schedule generations, newest first
+// @Tags         admin
+// @Produce      json
+// @Success      200 {array}  models.ScheduleGeneration
+// @Failure      500 {object} map[string]string
+// @Security     BearerAuth
+// @Router       /admin/generations [get]
+func ListGenerations(c *gin.Context) {
+	gens, err := db.ListGenerations(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch generations"})
+		return
+	}
+	c.JSON(http.StatusOK, gens)
+}
+
+// RollbackGeneration godoc
+// @Summary      Roll back the schedule
+// @Description  Re-activates the schedule generation published before the live one
+// @Tags         admin
+// @Produce      json
+// @Success      200 {object} models.ScheduleGeneration
+// @Failure      409 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Security     BearerAuth
+// @Router       /admin/generations/rollback [post]
+func RollbackGeneration(c *gin.Context) {
+	gen, err := db.RollbackGeneration(c.Request.Context())
+	if err != nil {
+		if errors.Is(err, db.ErrNoPreviousGeneration) || errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusConflict, gin.H{"error": "No previous generation to roll back to"})
+			return
+		}
+		log.Println("❌ Failed to roll back schedule:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to roll back"})
+		return
+	}
+	c.JSON(http.StatusOK, gen)
+}
+
+// ActivateGeneration godoc
+// @Summary      Activate a schedule generation
+// @Description  Makes the given generation the live schedule
+// @Tags         admin
+// @Produce      json
+// @Param        id   path  int  true  "Generation ID"
+// @Success      200 {object} models.ScheduleGeneration
+// @Failure      400 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Security     BearerAuth
+// @Router       /admin/generations/{id}/activate [post]
+func ActivateGeneration(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid generation id"})
+		return
+	}
+
+	gen, err := db.ActivateGeneration(c.Request.Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Generation not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to activate generation"})
+		return
+	}
+	c.JSON(http.StatusOK, gen)
+}
+
+// GetImportReport godoc
+// @Summary      Latest import report
+// @Description  Returns per-cell diagnostics from the most recent import attempt
+// @Tags         admin
+// @Produce      json
+// @Success      200 {object} excel.ParseReport
+// @Failure      404 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Security     BearerAuth
+// @Router       /admin/import/report [get]
+func GetImportReport(c *gin.Context) {
+	row, err := db.GetLatestImportReport(c.Request.Context())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No imports yet"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch report"})
+		return
+	}
+
+	var report excel.ParseReport
+	if err := json.Unmarshal(row.Report, &report); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":            row.ID,
+		"created_at":    row.CreatedAt,
+		"generation_id": row.GenerationID,
+		"report":        report,
+	})
+}
+
+// ListImportLogs godoc
+// @Summary      Import log
+// @Description  Returns the most recent checks of the schedule source, including skipped unchanged ones
+// @Tags         admin
+// @Produce      json
+// @Param        limit  query  int  false  "Number of entries (default 50)"
+// @Success      200 {array}  models.ImportLog
+// @Failure      500 {object} map[string]string
+// @Security     BearerAuth
+// @Router       /admin/import/log [get]
+func ListImportLogs(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 500 {
+		limit = 50
+	}
+
+	logs, err := db.ListImportLogs(c.Request.Context(), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch import log"})
+		return
+	}
+	c.JSON(http.StatusOK, logs)
+}
+
+// PreviewImport godoc
+// @Summary      Dry-run an import
+// @Description  Parses an uploaded xlsx without saving and returns lessons that would be added, removed and changed
+// @Tags         admin
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file  formData  file  true  "Timetable workbook (.xlsx)"
+// @Success      200 {object} excel.Preview
+// @Failure      400 {object} map[string]string
+// @Failure      422 {object} map[string]interface{}
+// @Failure      500 {object} map[string]string
+// @Security     BearerAuth
+// @Router       /admin/import/preview [post]
+func PreviewImport(importer *excel.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		fh, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Missing file"})
+			return
+		}
+
+		file, err := fh.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read file"})
+			return
+		}
+		defer file.Close()
+
+		src, err := excel.NewUploadSource(fh.Filename, file)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read file"})
+			return
+		}
+
+		preview, err := importer.Preview(c.Request.Context(), src)
+		if err != nil {
+			log.Println("❌ Failed to preview import:", err)
+			if preview != nil {
+				c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Failed to parse Excel", "problems": excel.ParseErrors(err), "report": preview.Report})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to preview import"})
+			return
+		}
+
+		c.JSON(http.StatusOK, preview)
+	}
+}
+
+// ListParallels godoc
+// @Summary      List parallel switches
+// @Description  Returns grade parallels an admin has switched on or off. Parallels not listed are imported.
+// @Tags         admin
+// @Produce      json
+// @Success      200 {array}  models.ParallelSetting
+// @Failure      500 {object} map[string]string
+// @Security     BearerAuth
+// @Router       /admin/parallels [get]
+func ListParallels(c *gin.Context) {
+	settings, err := db.ListParallelSettings(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch parallels"})
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
+
+// SetParallelRequest is the request body for switching a parallel
+type SetParallelRequest struct {
+	Enabled *bool `json:"enabled"`
+}
+
+// SetParallelEnabled godoc
+// @Summary      Switch a parallel on or off
+// @Description  Enables or disables importing of one grade parallel. Takes effect on the next import, even if the workbook is unchanged.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        grade  path  int                 true  "Grade"
+// @Param        body   body  SetParallelRequest  true  "Switch"
+// @Success      200 {object} map[string]string
+// @Failure      400 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Security     BearerAuth
+// @Router       /admin/parallels/{grade} [put]
+func SetParallelEnabled(c *gin.Context) {
+	grade, err := strconv.Atoi(c.Param("grade"))
+	if err != nil || grade < 1 || grade > 12 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid grade"})
+		return
+	}
+
+	var req SetParallelRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Enabled == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	if err := db.SetParallelEnabled(c.Request.Context(), grade, *req.Enabled); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update parallel"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Parallel updated"})
+}
@@ -0,0 +1,325 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/in-nis/cnis-back/internal/calendar"
+	"github.com/in-nis/cnis-back/internal/config"
+	"github.com/in-nis/cnis-back/internal/db"
+	"github.com/in-nis/cnis-back/internal/models"
+)
+
+// maxRangeDays caps how far a dated schedule can be expanded in one request.
+const maxRangeDays = 366
+
+// schoolLocation loads the configured school time zone, falling back to UTC.
+func schoolLocation(cfg *config.Config) *time.Location {
+	loc, err := time.LoadLocation(cfg.SchoolTimeZone)
+	if err != nil {
+		log.Printf("⚠️ Unknown SCHOOL_TIMEZONE %q, using UTC: %v\n", cfg.SchoolTimeZone, err)
+		return time.UTC
+	}
+	return loc
+}
+
+// loadCalendar reads the terms and the calendar exceptions between from and to.
+func loadCalendar(ctx context.Context, from, to time.Time) (*calendar.Calendar, error) {
+	terms, err := db.ListTerms(ctx)
+	if err != nil {
+		return nil, err
+	}
+	days, err := db.ListCalendarDays(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return calendar.New(terms, days), nil
+}
+
+// dateRange reads ?from= and ?to= (YYYY-MM-DD). From defaults to today in
+// loc and to to a week after from.
+func dateRange(c *gin.Context, loc *time.Location) (from, to time.Time, err error) {
+	from = calendar.Date(time.Now(), loc)
+	if s := c.Query("from"); s != "" {
+		if from, err = time.ParseInLocation(time.DateOnly, s, loc); err != nil {
+			return from, to, fmt.Errorf("invalid from date %q", s)
+		}
+	}
+	to = from.AddDate(0, 0, 6)
+	if s := c.Query("to"); s != "" {
+		if to, err = time.ParseInLocation(time.DateOnly, s, loc); err != nil {
+			return from, to, fmt.Errorf("invalid to date %q", s)
+		}
+	}
+
+	switch {
+	case to.Before(from):
+		return from, to, fmt.Errorf("to is before from")
+	case to.After(from.AddDate(0, 0, maxRangeDays)):
+		return from, to, fmt.Errorf("range is longer than %d days", maxRangeDays)
+	}
+	return from, to, nil
+}
+
+// GetUserDatedSchedule godoc
+// @Summary      Get the user's lessons on real dates
+// @Description  Expands the user's weekly schedule into dated lessons between from and to (inclusive, YYYY-MM-DD),
+// @Description  skipping days outside terms, holidays and non-school days and applying day swaps.
+// @Description  Times are in the school's time zone. Defaults to the week starting today.
+// @Tags         user
+// @Produce      json
+// @Param        from  query  string  false  "First date, YYYY-MM-DD"
+// @Param        to    query  string  false  "Last date, YYYY-MM-DD"
+// @Success      200 {object} map[string]interface{}
+// @Failure      400 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Security     BearerAuth
+// @Router       /user/schedule/dates [get]
+func GetUserDatedSchedule(loc *time.Location) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		from, to, err := dateRange(c, loc)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		lessons, ok := loadUserLessons(c)
+		if !ok {
+			return
+		}
+
+		cal, err := loadCalendar(c.Request.Context(), from, to)
+		if err != nil {
+			log.Println("❌ Failed to load calendar:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load calendar"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"from":      from.Format(time.DateOnly),
+			"to":        to.Format(time.DateOnly),
+			"time_zone": loc.String(),
+			"lessons":   cal.Expand(lessons, from, to, loc),
+		})
+	}
+}
+
+// nowLookahead is how many days ahead GetUserScheduleNow searches for the
+// next lesson, enough to get past a weekend or a short holiday.
+const nowLookahead = 14
+
+// GetUserScheduleNow godoc
+// @Summary      Get the user's current and next lesson
+// @Description  Returns the lesson running now, the next lesson, the minutes left in the lesson or break,
+// @Description  and whether now is a break between two lessons. Uses the school's time zone and calendar.
+// @Tags         user
+// @Produce      json
+// @Success      200 {object} calendar.Snapshot
+// @Failure      400 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Security     BearerAuth
+// @Router       /user/schedule/now [get]
+func GetUserScheduleNow(loc *time.Location) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		now := time.Now().In(loc)
+		from := calendar.Date(now, loc)
+		to := from.AddDate(0, 0, nowLookahead)
+
+		lessons, ok := loadUserLessons(c)
+		if !ok {
+			return
+		}
+
+		cal, err := loadCalendar(c.Request.Context(), from, to)
+		if err != nil {
+			log.Println("❌ Failed to load calendar:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load calendar"})
+			return
+		}
+
+		c.JSON(http.StatusOK, calendar.Snap(cal.Expand(lessons, from, to, loc), now))
+	}
+}
+
+// -------------------- ADMIN --------------------
+
+// ListCalendar godoc
+// @Summary      List the academic calendar
+// @Description  Returns all terms and the calendar exceptions between from and to (YYYY-MM-DD).
+// @Tags         admin
+// @Produce      json
+// @Param        from  query  string  false  "First date, YYYY-MM-DD"
+// @Param        to    query  string  false  "Last date, YYYY-MM-DD"
+// @Success      200 {object} map[string]interface{}
+// @Failure      400 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Security     BearerAuth
+// @Router       /admin/calendar [get]
+func ListCalendar(loc *time.Location) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		from, to, err := dateRange(c, loc)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		terms, err := db.ListTerms(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch terms"})
+			return
+		}
+		days, err := db.ListCalendarDays(c.Request.Context(), from, to)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch calendar days"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"terms": terms, "days": days})
+	}
+}
+
+// CreateTermRequest is the request body for adding a term
+type CreateTermRequest struct {
+	Name      string `json:"name"`
+	StartDate string `json:"start_date"` // YYYY-MM-DD
+	EndDate   string `json:"end_date"`   // YYYY-MM-DD
+}
+
+// CreateTerm godoc
+// @Summary      Add a term
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        body  body  CreateTermRequest  true  "Term"
+// @Success      200 {object} models.Term
+// @Failure      400 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Security     BearerAuth
+// @Router       /admin/calendar/terms [post]
+func CreateTerm(c *gin.Context) {
+	var req CreateTermRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	start, err1 := time.Parse(time.DateOnly, req.StartDate)
+	end, err2 := time.Parse(time.DateOnly, req.EndDate)
+	if err1 != nil || err2 != nil || end.Before(start) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid term dates"})
+		return
+	}
+
+	term := models.Term{Name: req.Name, StartDate: start, EndDate: end}
+	if err := db.CreateTerm(c.Request.Context(), &term); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save term"})
+		return
+	}
+	c.JSON(http.StatusOK, term)
+}
+
+// DeleteTerm godoc
+// @Summary      Delete a term
+// @Tags         admin
+// @Produce      json
+// @Param        id  path  int  true  "Term ID"
+// @Success      200 {object} map[string]string
+// @Failure      400 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Security     BearerAuth
+// @Router       /admin/calendar/terms/{id} [delete]
+func DeleteTerm(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid term id"})
+		return
+	}
+
+	if err := db.DeleteTerm(c.Request.Context(), uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete term"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Term deleted"})
+}
+
+// SetCalendarDayRequest is the request body for a calendar exception
+type SetCalendarDayRequest struct {
+	Kind  string `json:"kind"`   // holiday, non_school or swap
+	AsDay int    `json:"as_day"` // for swaps: weekday whose lessons run, 1=Mon
+	Name  string `json:"name"`
+}
+
+// SetCalendarDay godoc
+// @Summary      Set a calendar exception
+// @Description  Marks a date as a holiday, a non-school day, or a swap day that follows another weekday's timetable.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        date  path  string                 true  "Date, YYYY-MM-DD"
+// @Param        body  body  SetCalendarDayRequest  true  "Exception"
+// @Success      200 {object} models.CalendarDay
+// @Failure      400 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Security     BearerAuth
+// @Router       /admin/calendar/days/{date} [put]
+func SetCalendarDay(c *gin.Context) {
+	date, err := time.Parse(time.DateOnly, c.Param("date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date"})
+		return
+	}
+
+	var req SetCalendarDayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	switch req.Kind {
+	case models.CalendarHoliday, models.CalendarNonSchool:
+		req.AsDay = 0
+	case models.CalendarSwap:
+		if req.AsDay < 1 || req.AsDay > 7 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "as_day must be between 1 and 7"})
+			return
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "kind must be holiday, non_school or swap"})
+		return
+	}
+
+	day := models.CalendarDay{Date: date, Kind: req.Kind, AsDay: req.AsDay, Name: req.Name}
+	if err := db.SetCalendarDay(c.Request.Context(), &day); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save calendar day"})
+		return
+	}
+	c.JSON(http.StatusOK, day)
+}
+
+// DeleteCalendarDay godoc
+// @Summary      Remove a calendar exception
+// @Tags         admin
+// @Produce      json
+// @Param        date  path  string  true  "Date, YYYY-MM-DD"
+// @Success      200 {object} map[string]string
+// @Failure      400 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Security     BearerAuth
+// @Router       /admin/calendar/days/{date} [delete]
+func DeleteCalendarDay(c *gin.Context) {
+	date, err := time.Parse(time.DateOnly, c.Param("date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date"})
+		return
+	}
+
+	if err := db.DeleteCalendarDay(c.Request.Context(), date); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete calendar day"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Calendar day deleted"})
+}
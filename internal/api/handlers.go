@@ -2,6 +2,7 @@ package api
 
 import (
     "context"
+	"errors"
 	"log"
     "net/http"
 	"strconv"
@@ -167,28 +168,48 @@ func GetMe(c *gin.Context) {
 
 // ParseLessons godoc
 // @Summary      Parse Excel and save lessons
-// @Description  Parses sheet.xlsx and saves lessons into DB
+// @Description  Parses the configured schedule source and publishes it as a new schedule generation.
+// @Description  An unchanged workbook is skipped unless force is set.
+// @Description  With strict set, any known problem (unknown day, bad time range, unknown grade header) fails the import.
 // @Tags         lessons
 // @Produce      json
-// @Success      200 {object} map[string]string
+// @Param        force   query  bool  false  "Re-import even if the workbook is unchanged"
+// @Param        strict  query  bool  false  "Fail on any known parse problem"
+// @Success      200 {object} map[string]interface{}
+// @Failure      422 {object} map[string]interface{}
 // @Failure      500 {object} map[string]string
-// @Router       /lessons/parse [post]
-func ParseLessons(c *gin.Context) {
-	path := "sheet.xlsx"
-
-	if err := db.DeleteAllLessons(context.Background()); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clear lessons"})
-		return
-	}
+// @Security     BearerAuth
+// @Router       /admin/lessons/reload [post]
+func ParseLessons(importer *excel.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var opts excel.ImportOptions
+		opts.Force, _ = strconv.ParseBool(c.Query("force"))
+		opts.Strict, _ = strconv.ParseBool(c.Query("strict"))
+
+		gen, report, err := importer.Import(c.Request.Context(), opts)
+		if errors.Is(err, excel.ErrNotModified) {
+			c.JSON(http.StatusOK, gin.H{"message": "Schedule unchanged, nothing imported"})
+			return
+		}
+		if problems := excel.ParseErrors(err); len(problems) > 0 {
+			log.Println("❌ Schedule has problems:", err)
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Schedule has problems", "problems": problems, "report": report})
+			return
+		}
+		if err != nil {
+			log.Println("❌ Failed to import schedule:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse Excel", "report": report})
+			return
+		}
 
-	lessons, err := excel.ParseExcel(path)
-	if err != nil {
-		log.Println("❌ Failed to parse Excel:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse Excel"})
-		return
+		c.JSON(http.StatusOK, gin.H{
+			"message":    "Lessons parsed and saved",
+			"count":      gen.LessonCount,
+			"generation": gen.ID,
+			"warnings":   report.Count(excel.SeverityWarning),
+			"errors":     report.Count(excel.SeverityError),
+		})
 	}
-
-	c.JSON(http.StatusOK, gin.H{"message": "Lessons parsed and saved", "count": len(lessons)})
 }
 
 // GetLessonsByClassAndGroups godoc
@@ -246,18 +267,20 @@ func GetLessonsByClassAndGroups(c *gin.Context) {
 		return
 	}
 
-	// Group lessons by LessonDay
+	c.JSON(http.StatusOK, groupByDay(lessons))
+}
+
+// groupByDay groups lessons by LessonDay, each day sorted by LessonStart.
+func groupByDay(lessons []models.Lesson) map[int][]models.Lesson {
 	grouped := make(map[int][]models.Lesson)
 	for _, l := range lessons {
 		grouped[l.LessonDay] = append(grouped[l.LessonDay], l)
 	}
 
-	// Sort each day's lessons by LessonStart
 	for day := range grouped {
 		sort.Slice(grouped[day], func(i, j int) bool {
 			return grouped[day][i].LessonStart.Before(grouped[day][j].LessonStart)
 		})
 	}
-
-	c.JSON(http.StatusOK, grouped)
+	return grouped
 }
\ No newline at end of file
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/in-nis/cnis-back/internal/db"
+)
+
+// ListRooms godoc
+// @Summary      List rooms
+// @Description  Returns the rooms used in the live schedule, sorted by building and number
+// @Tags         rooms
+// @Produce      json
+// @Success      200 {array} models.Room
+// @Failure      500 {object} map[string]string
+// @Router       /rooms [get]
+func ListRooms(c *gin.Context) {
+	rooms, err := db.ListRooms(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch rooms"})
+		return
+	}
+	c.JSON(http.StatusOK, rooms)
+}
+
+// GetRoomSchedule godoc
+// @Summary      Get a room's schedule
+// @Description  Returns the lessons held in the room, grouped by day (1=Mon) and sorted by start time
+// @Tags         rooms
+// @Produce      json
+// @Param        id   path  int  true  "Room ID"
+// @Success      200 {object} map[string]interface{}
+// @Failure      400 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /rooms/{id}/schedule [get]
+func GetRoomSchedule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid room id"})
+		return
+	}
+
+	room, err := db.GetRoom(c.Request.Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Room not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch room"})
+		return
+	}
+
+	lessons, err := db.GetRoomLessons(c.Request.Context(), room.Key)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch lessons"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"room": room, "schedule": groupByDay(lessons)})
+}
+
+// ListFreeRooms godoc
+// @Summary      Find free rooms
+// @Description  Returns the rooms of the live schedule with no lesson on the given day between start and end.
+// @Description  Cancelled lessons do not occupy their room.
+// @Tags         rooms
+// @Produce      json
+// @Param        day    query  int     true  "Day of week, 1=Mon"
+// @Param        start  query  string  true  "Start time, HH:MM"
+// @Param        end    query  string  true  "End time, HH:MM"
+// @Success      200 {array} models.Room
+// @Failure      400 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /rooms/free [get]
+func ListFreeRooms(c *gin.Context) {
+	day, err := strconv.Atoi(c.Query("day"))
+	if err != nil || day < 1 || day > 7 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "day must be between 1 and 7"})
+		return
+	}
+
+	start, err1 := time.Parse("15:04", c.Query("start"))
+	end, err2 := time.Parse("15:04", c.Query("end"))
+	if err1 != nil || err2 != nil || !end.After(start) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "start and end must be HH:MM with end after start"})
+		return
+	}
+
+	rooms, err := db.ListFreeRooms(c.Request.Context(), day, start.Format(time.TimeOnly), end.Format(time.TimeOnly))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch rooms"})
+		return
+	}
+	c.JSON(http.StatusOK, rooms)
+}
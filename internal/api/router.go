@@ -5,6 +5,7 @@ import (
     "github.com/in-nis/cnis-back/internal/auth"
 	"github.com/in-nis/cnis-back/internal/db"
 	"github.com/in-nis/cnis-back/internal/config"
+	"github.com/in-nis/cnis-back/internal/excel"
 	_ "github.com/in-nis/cnis-back/docs"
 	"github.com/swaggo/files"
 	"github.com/swaggo/gin-swagger"
@@ -19,8 +20,9 @@ import (
 // @securityDefinitions.apikey BearerAuth
 // @in header
 // @name Authorization
-func SetupRouter(cfg *config.Config) *gin.Engine {
+func SetupRouter(cfg *config.Config, importer *excel.Service) *gin.Engine {
 	auth.InitGoogle(cfg)
+	loc := schoolLocation(cfg)
 
     r := gin.Default()
 
@@ -41,6 +43,11 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
     r.GET("/auth/google/callback", auth.GoogleCallbackHandler(cfg))
 	r.POST("/auth/refresh", auth.RefreshHandler(cfg))
 	r.GET("/lessons/filter", GetLessonsByClassAndGroups)
+	r.GET("/teachers", ListTeachers)
+	r.GET("/teachers/:id/schedule", GetTeacherSchedule)
+	r.GET("/rooms", ListRooms)
+	r.GET("/rooms/free", ListFreeRooms)
+	r.GET("/rooms/:id/schedule", GetRoomSchedule)
 
 	// Protected
     authGroup := r.Group("/user")
@@ -51,7 +58,28 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
         authGroup.POST("/groups", AddUserGroup)
         authGroup.DELETE("/groups/:id", DeleteUserGroup)
 		authGroup.GET("/me", GetMe)
-		authGroup.POST("/lessons/reload", ParseLessons)
+		authGroup.GET("/schedule", GetUserSchedule)
+		authGroup.GET("/schedule/dates", GetUserDatedSchedule(loc))
+		authGroup.GET("/schedule/now", GetUserScheduleNow(loc))
+    }
+
+    adminGroup := r.Group("/admin")
+    adminGroup.Use(auth.AuthMiddleware(cfg), auth.AdminMiddleware(cfg))
+    {
+        adminGroup.POST("/lessons/reload", ParseLessons(importer))
+        adminGroup.GET("/generations", ListGenerations)
+        adminGroup.POST("/generations/rollback", RollbackGeneration)
+        adminGroup.POST("/generations/:id/activate", ActivateGeneration)
+        adminGroup.GET("/import/report", GetImportReport)
+        adminGroup.GET("/import/log", ListImportLogs)
+        adminGroup.POST("/import/preview", PreviewImport(importer))
+        adminGroup.GET("/parallels", ListParallels)
+        adminGroup.PUT("/parallels/:grade", SetParallelEnabled)
+        adminGroup.GET("/calendar", ListCalendar(loc))
+        adminGroup.POST("/calendar/terms", CreateTerm)
+        adminGroup.DELETE("/calendar/terms/:id", DeleteTerm)
+        adminGroup.PUT("/calendar/days/:date", SetCalendarDay)
+        adminGroup.DELETE("/calendar/days/:date", DeleteCalendarDay)
     }
 
     return r
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/in-nis/cnis-back/internal/db"
+	"github.com/in-nis/cnis-back/internal/models"
+)
+
+var errNoGrade = errors.New("user has no grade set")
+
+// ScheduleLesson is a lesson in a user's schedule. ConflictsWith lists the
+// IDs of the user's other lessons that overlap it.
+type ScheduleLesson struct {
+	models.Lesson
+	Conflict      bool   `json:"conflict"`
+	ConflictsWith []uint `json:"conflicts_with,omitempty"`
+}
+
+// userLessons loads the live lessons of the user's class and groups.
+func userLessons(ctx context.Context, email string) (*models.User, []models.Lesson, error) {
+	user, err := db.GetUserByEmail(ctx, email)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user.Grade == 0 {
+		return user, nil, errNoGrade
+	}
+
+	filters := make([]models.LessonGroupFilter, 0, len(user.Groups))
+	for _, g := range user.Groups {
+		filters = append(filters, models.LessonGroupFilter{LessonName: g.LessonName, LessonGroup: g.LessonGroup})
+	}
+
+	lessons, err := db.GetLessonsByClassAndGroups(ctx, user.Grade, user.GradeLetter, filters)
+	if err != nil {
+		return user, nil, err
+	}
+	return user, lessons, nil
+}
+
+// markConflicts flags lessons of the same day whose times overlap, which
+// happens when the user has picked two groups that meet at once.
+func markConflicts(days map[int][]models.Lesson) map[int][]ScheduleLesson {
+	out := make(map[int][]ScheduleLesson, len(days))
+	for day, lessons := range days {
+		marked := make([]ScheduleLesson, len(lessons))
+		for i, l := range lessons {
+			marked[i].Lesson = l
+		}
+
+		for i := range marked {
+			for j := i + 1; j < len(marked); j++ {
+				a, b := &marked[i], &marked[j]
+				if !b.LessonStart.Before(a.LessonEnd) {
+					break // sorted by start, so nothing later overlaps a either
+				}
+				a.Conflict, b.Conflict = true, true
+				a.ConflictsWith = append(a.ConflictsWith, b.ID)
+				b.ConflictsWith = append(b.ConflictsWith, a.ID)
+			}
+		}
+		out[day] = marked
+	}
+	return out
+}
+
+// GetUserSchedule godoc
+// @Summary      Get the user's schedule
+// @Description  Returns the lessons of the user's class and groups, grouped by day (1=Mon) and sorted by start time.
+// @Description  Lessons that overlap another lesson of the user are flagged with conflict.
+// @Tags         user
+// @Produce      json
+// @Success      200 {object} map[int][]ScheduleLesson
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Security     BearerAuth
+// @Router       /user/schedule [get]
+func GetUserSchedule(c *gin.Context) {
+	lessons, ok := loadUserLessons(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, markConflicts(groupByDay(lessons)))
+}
+
+// loadUserLessons wraps userLessons for handlers: on failure it writes the
+// error response and returns false.
+func loadUserLessons(c *gin.Context) ([]models.Lesson, bool) {
+	user, lessons, err := userLessons(c.Request.Context(), c.GetString("email"))
+	switch {
+	case errors.Is(err, errNoGrade):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Grade not set"})
+		return nil, false
+	case errors.Is(err, gorm.ErrRecordNotFound) && user == nil:
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return nil, false
+	case err != nil && user == nil:
+		log.Println("❌ Failed to fetch user:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		return nil, false
+	case err != nil:
+		log.Println("❌ Failed to fetch schedule:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch lessons"})
+		return nil, false
+	}
+	return lessons, true
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/in-nis/cnis-back/internal/db"
+)
+
+// ListTeachers godoc
+// @Summary      List teachers
+// @Description  Returns the teachers who have lessons in the live schedule, sorted by name
+// @Tags         teachers
+// @Produce      json
+// @Success      200 {array} models.Teacher
+// @Failure      500 {object} map[string]string
+// @Router       /teachers [get]
+func ListTeachers(c *gin.Context) {
+	teachers, err := db.ListTeachers(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch teachers"})
+		return
+	}
+	c.JSON(http.StatusOK, teachers)
+}
+
+// GetTeacherSchedule godoc
+// @Summary      Get a teacher's schedule
+// @Description  Returns the teacher's lessons across all classes, grouped by day (1=Mon) and sorted by start time.
+// @Description  A lesson taught to several classes at once appears once per class with the same stream_id.
+// @Tags         teachers
+// @Produce      json
+// @Param        id   path  int  true  "Teacher ID"
+// @Success      200 {object} map[string]interface{}
+// @Failure      400 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /teachers/{id}/schedule [get]
+func GetTeacherSchedule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid teacher id"})
+		return
+	}
+
+	teacher, err := db.GetTeacher(c.Request.Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Teacher not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch teacher"})
+		return
+	}
+
+	lessons, err := db.GetTeacherLessons(c.Request.Context(), teacher.Key)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch lessons"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"teacher": teacher, "schedule": groupByDay(lessons)})
+}
@@ -44,4 +44,19 @@ func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
         c.Set("email", claims["email"])
         c.Next()
     }
-}
\ No newline at end of file
+}
+
+// AdminMiddleware lets through only users listed in ADMIN_EMAILS.
+// It must run after AuthMiddleware.
+func AdminMiddleware(cfg *config.Config) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        email := c.GetString("email")
+        for _, admin := range cfg.AdminEmails {
+            if strings.EqualFold(admin, email) {
+                c.Next()
+                return
+            }
+        }
+        c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+    }
+}
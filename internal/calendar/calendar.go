@@ -0,0 +1,170 @@
+// Package calendar turns the weekly timetable into lessons on real dates,
+// following the academic calendar.
+package calendar
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/in-nis/cnis-back/internal/models"
+)
+
+// Calendar answers which timetable runs on a date.
+type Calendar struct {
+	terms []models.Term
+	days  map[string]models.CalendarDay // by date, "2006-01-02"
+}
+
+// New builds a calendar from terms and date exceptions. Without terms every
+// date is a school day unless an exception says otherwise.
+func New(terms []models.Term, days []models.CalendarDay) *Calendar {
+	c := &Calendar{terms: terms, days: make(map[string]models.CalendarDay, len(days))}
+	for _, d := range days {
+		c.days[d.Date.Format(time.DateOnly)] = d
+	}
+	return c
+}
+
+// Day returns the exception for date, if there is one.
+func (c *Calendar) Day(date time.Time) (models.CalendarDay, bool) {
+	d, ok := c.days[date.Format(time.DateOnly)]
+	return d, ok
+}
+
+// Weekday returns whose timetable (1=Mon … 7=Sun) runs on date, or 0 when
+// there are no lessons: outside every term, on holidays and non-school days.
+func (c *Calendar) Weekday(date time.Time) int {
+	if d, ok := c.Day(date); ok {
+		switch d.Kind {
+		case models.CalendarHoliday, models.CalendarNonSchool:
+			return 0
+		case models.CalendarSwap:
+			return d.AsDay
+		}
+	}
+	if !c.inTerm(date) {
+		return 0
+	}
+	return isoWeekday(date)
+}
+
+func (c *Calendar) inTerm(date time.Time) bool {
+	if len(c.terms) == 0 {
+		return true
+	}
+	key := date.Format(time.DateOnly)
+	for _, t := range c.terms {
+		if key >= t.StartDate.Format(time.DateOnly) && key <= t.EndDate.Format(time.DateOnly) {
+			return true
+		}
+	}
+	return false
+}
+
+// isoWeekday numbers days the way lessons do: 1=Mon … 7=Sun.
+func isoWeekday(date time.Time) int {
+	if wd := int(date.Weekday()); wd != 0 {
+		return wd
+	}
+	return 7
+}
+
+// Occurrence is a lesson on a concrete date.
+type Occurrence struct {
+	Date   string        `json:"date"` // "2006-01-02"
+	Start  time.Time     `json:"start"`
+	End    time.Time     `json:"end"`
+	Lesson models.Lesson `json:"lesson"`
+}
+
+// Expand places the weekly lessons on every date from from to to, inclusive,
+// in the school's time zone loc. The result is sorted by start time.
+func (c *Calendar) Expand(lessons []models.Lesson, from, to time.Time, loc *time.Location) []Occurrence {
+	byDay := make(map[int][]models.Lesson)
+	for _, l := range lessons {
+		byDay[l.LessonDay] = append(byDay[l.LessonDay], l)
+	}
+
+	out := []Occurrence{}
+	for date := Date(from, loc); !date.After(to); date = date.AddDate(0, 0, 1) {
+		for _, l := range byDay[c.Weekday(date)] {
+			out = append(out, Occurrence{
+				Date:   date.Format(time.DateOnly),
+				Start:  At(date, l.LessonStart, loc),
+				End:    At(date, l.LessonEnd, loc),
+				Lesson: l,
+			})
+		}
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Start.Before(out[j].Start)
+	})
+	return out
+}
+
+// Date is midnight of t's calendar day in loc.
+func Date(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// At puts the clock time of a lesson's start or end on date in loc.
+func At(date, clock time.Time, loc *time.Location) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), clock.Hour(), clock.Minute(), 0, 0, loc)
+}
+
+// Snapshot is what is happening at one moment: the running lesson, the next
+// one and whether the moment falls in a break between two lessons of a day.
+type Snapshot struct {
+	Now              time.Time   `json:"now"`
+	Current          *Occurrence `json:"current"`
+	Next             *Occurrence `json:"next"`
+	MinutesLeft      int         `json:"minutes_left"`       // until Current ends, or until the break ends
+	MinutesUntilNext int         `json:"minutes_until_next"` // 0 when there is no next lesson
+	Break            bool        `json:"break"`
+	SchoolDay        bool        `json:"school_day"` // today has lessons
+}
+
+// Snap reports what is happening at now, given occurrences sorted by start as
+// returned by Expand. Lessons later than today are only used for Next.
+func Snap(occ []Occurrence, now time.Time) Snapshot {
+	s := Snapshot{Now: now}
+	today := now.Format(time.DateOnly)
+	endedToday := false
+
+	for i := range occ {
+		o := &occ[i]
+		if o.Date == today {
+			s.SchoolDay = true
+		}
+		switch {
+		case !o.End.After(now):
+			endedToday = endedToday || o.Date == today
+		case !o.Start.After(now):
+			if s.Current == nil {
+				s.Current = o
+			}
+		case s.Next == nil:
+			s.Next = o
+		}
+	}
+
+	if s.Next != nil {
+		s.MinutesUntilNext = minutesUntil(now, s.Next.Start)
+	}
+	switch {
+	case s.Current != nil:
+		s.MinutesLeft = minutesUntil(now, s.Current.End)
+	case endedToday && s.Next != nil && s.Next.Date == today:
+		s.Break = true
+		s.MinutesLeft = s.MinutesUntilNext
+	}
+	return s
+}
+
+// minutesUntil rounds up, so a lesson with 30 seconds left still shows 1.
+func minutesUntil(now, t time.Time) int {
+	return int(math.Ceil(t.Sub(now).Minutes()))
+}
@@ -2,6 +2,9 @@ package config
 
 import (
     "os"
+    "strconv"
+    "strings"
+    "time"
 )
 
 type Config struct {
@@ -9,6 +12,14 @@ type Config struct {
     GoogleClientID string
     GoogleSecret   string
 	JWT_SECRET string 
+    ScheduleSource string // path, URL or dir: spec, see excel.NewSource
+    ScheduleLayout string // YAML/JSON layout descriptor, see excel.LoadLayout
+    ScheduleStrict bool   // fail imports on any known parse problem
+    SchoolTimeZone string // IANA name, e.g. Asia/Almaty; lesson times are in it
+    DownloadTimeout  time.Duration
+    DownloadRetries  int
+    DownloadMaxBytes int64
+    AdminEmails    []string
 }
 
 func Load() *Config {
@@ -17,6 +28,14 @@ func Load() *Config {
         GoogleClientID: getEnv("GOOGLE_CLIENT_ID", ""),
         GoogleSecret:   getEnv("GOOGLE_CLIENT_SECRET", ""),
 		JWT_SECRET: getEnv("JWT_SECRET", ""),
+        ScheduleSource: getEnv("SCHEDULE_SOURCE", "sheet.xlsx"),
+        ScheduleLayout: getEnv("SCHEDULE_LAYOUT", ""),
+        ScheduleStrict: getBool("SCHEDULE_STRICT", false),
+        SchoolTimeZone: getEnv("SCHOOL_TIMEZONE", "Asia/Almaty"),
+        DownloadTimeout:  getDuration("DOWNLOAD_TIMEOUT", 30*time.Second),
+        DownloadRetries:  getInt("DOWNLOAD_RETRIES", 3),
+        DownloadMaxBytes: int64(getInt("DOWNLOAD_MAX_BYTES", 20<<20)),
+        AdminEmails:    getList("ADMIN_EMAILS"),
     }
 }
 
@@ -25,4 +44,35 @@ func getEnv(key, fallback string) string {
         return value
     }
     return fallback
-}
\ No newline at end of file
+}
+
+func getList(key string) []string {
+    var out []string
+    for _, v := range strings.Split(getEnv(key, ""), ",") {
+        if v = strings.TrimSpace(v); v != "" {
+            out = append(out, v)
+        }
+    }
+    return out
+}
+
+func getInt(key string, fallback int) int {
+    if n, err := strconv.Atoi(getEnv(key, "")); err == nil {
+        return n
+    }
+    return fallback
+}
+
+func getBool(key string, fallback bool) bool {
+    if b, err := strconv.ParseBool(getEnv(key, "")); err == nil {
+        return b
+    }
+    return fallback
+}
+
+func getDuration(key string, fallback time.Duration) time.Duration {
+    if d, err := time.ParseDuration(getEnv(key, "")); err == nil {
+        return d
+    }
+    return fallback
+}
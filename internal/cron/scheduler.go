@@ -2,40 +2,39 @@ package cron
 
 import (
 	"context"
+	"errors"
 	"log"
 
 	"github.com/in-nis/cnis-back/internal/excel"
 	"github.com/robfig/cron/v3"
-	"github.com/in-nis/cnis-back/internal/db"
 )
 
-func StartJobs() {
+func StartJobs(importer *excel.Service) {
 	c := cron.New()
 
 	c.AddFunc("@daily", func() {
 		log.Println("Running Excel parser job...")
 
-		// path, err := excel.GetExcel()
-		// if err != nil {
-		// 	log.Println("❌ Failed to download Excel:", err)
-		// 	return
-		// }
-
-		path := "sheet.xlsx"
-
-		lessons, err := excel.ParseExcel(path)
-		if err != nil {
-			log.Println("❌ Failed to parse Excel:", err)
+		gen, report, err := importer.Import(context.Background(), excel.ImportOptions{})
+		if errors.Is(err, excel.ErrNotModified) {
+			log.Println("⏸️ Schedule unchanged, nothing to import")
 			return
 		}
-
-		if err := db.SaveLessons(context.Background(), lessons); err != nil {
-			log.Println("❌ Failed to save lessons:", err)
+		if problems := excel.ParseErrors(err); len(problems) > 0 {
+			log.Printf("❌ Schedule not imported, %d problems in the workbook:\n", len(problems))
+			for _, p := range problems {
+				log.Printf("   ❌ [%s] %v\n", p.Code, p)
+			}
+			return
+		}
+		if err != nil {
+			log.Println("❌ Failed to import schedule:", err)
 			return
 		}
 
-		log.Printf("✅ Saved %d lessons\n", len(lessons))
+		log.Printf("✅ Saved %d lessons (%d warnings, %d errors)\n",
+			gen.LessonCount, report.Count(excel.SeverityWarning), report.Count(excel.SeverityError))
 	})
 
 	c.Start()
-}
\ No newline at end of file
+}
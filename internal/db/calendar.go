@@ -0,0 +1,50 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm/clause"
+
+	"github.com/in-nis/cnis-back/internal/models"
+)
+
+func ListTerms(ctx context.Context) ([]models.Term, error) {
+	var terms []models.Term
+	if err := DB.WithContext(ctx).Order("start_date").Find(&terms).Error; err != nil {
+		return nil, err
+	}
+	return terms, nil
+}
+
+func CreateTerm(ctx context.Context, term *models.Term) error {
+	return DB.WithContext(ctx).Create(term).Error
+}
+
+func DeleteTerm(ctx context.Context, id uint) error {
+	return DB.WithContext(ctx).Delete(&models.Term{}, id).Error
+}
+
+// ListCalendarDays returns the calendar exceptions between from and to, inclusive.
+func ListCalendarDays(ctx context.Context, from, to time.Time) ([]models.CalendarDay, error) {
+	var days []models.CalendarDay
+	if err := DB.WithContext(ctx).
+		Where("date BETWEEN ? AND ?", from.Format(time.DateOnly), to.Format(time.DateOnly)).
+		Order("date").
+		Find(&days).Error; err != nil {
+		return nil, err
+	}
+	return days, nil
+}
+
+// SetCalendarDay creates or replaces the exception for day.Date.
+func SetCalendarDay(ctx context.Context, day *models.CalendarDay) error {
+	return DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"kind", "as_day", "name"}),
+	}).Create(day).Error
+}
+
+func DeleteCalendarDay(ctx context.Context, date time.Time) error {
+	return DB.WithContext(ctx).Where("date = ?", date.Format(time.DateOnly)).Delete(&models.CalendarDay{}).Error
+}
@@ -21,10 +21,13 @@ func InitDB(dsn string) {
     }
 
     // AutoMigrate will create/update tables automatically
-    err = DB.AutoMigrate(&models.Lesson{}, &models.User{}, &models.UserGroup{})
+    err = DB.AutoMigrate(&models.Lesson{}, &models.ScheduleGeneration{}, &models.ImportReport{}, &models.ImportLog{}, &models.ParallelSetting{}, &models.Teacher{}, &models.Room{}, &models.Term{}, &models.CalendarDay{}, &models.User{}, &models.UserGroup{})
     if err != nil {
         log.Fatalf("failed to migrate database: %v", err)
     }
+    if err := adoptLegacyLessons(); err != nil {
+        log.Fatalf("failed to adopt existing lessons: %v", err)
+    }
 
     fmt.Println("✅ Database connected and migrated")
 }
@@ -33,14 +36,6 @@ func SaveLesson(ctx context.Context, l models.Lesson) error {
     return DB.WithContext(ctx).Create(&l).Error
 }
 
-func SaveLessons(ctx context.Context, lessons []models.Lesson) error {
-    return DB.WithContext(ctx).Create(&lessons).Error
-}
-
-func DeleteAllLessons(ctx context.Context) error {
-	return DB.WithContext(ctx).Where("1 = 1").Delete(&models.Lesson{}).Error
-}
-
 func SaveOrUpdateUser(ctx context.Context, u models.User) error {
     var existing models.User
     if err := DB.WithContext(ctx).Where("email = ?", u.Email).First(&existing).Error; err != nil {
@@ -103,11 +98,12 @@ func DeleteUserGroup(ctx context.Context, email, groupID string) error {
 func GetLessonsByClassAndGroups(ctx context.Context, grade int, letter string, filters []models.LessonGroupFilter) ([]models.Lesson, error) {
 	var lessons []models.Lesson
 
-	tx := DB.WithContext(ctx).Where("grade = ? AND grade_letter = ?", grade, letter)
+	match := DB.Where("grade = ? AND grade_letter = ?", grade, letter)
 	for _, f := range filters {
-		tx = tx.Or("grade = ? AND lesson_name = ? AND lesson_group = ?", grade, f.LessonName, f.LessonGroup)
+		match = match.Or("grade = ? AND lesson_name = ? AND lesson_group = ?", grade, f.LessonName, f.LessonGroup)
 	}
 
+	tx := DB.WithContext(ctx).Where("generation_id = (?)", activeGeneration(DB)).Where(match)
 	if err := tx.Find(&lessons).Error; err != nil {
 		return nil, err
 	}
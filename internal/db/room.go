@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/in-nis/cnis-back/internal/models"
+)
+
+// saveRooms creates or updates the rooms of a new generation.
+func saveRooms(tx *gorm.DB, rooms []models.Room) error {
+	if len(rooms) == 0 {
+		return nil
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"number", "building", "floor", "updated_at"}),
+	}).CreateInBatches(&rooms, 500).Error
+}
+
+// liveRoomKeys is a subquery selecting the room keys used by the live schedule.
+func liveRoomKeys(tx *gorm.DB) *gorm.DB {
+	return tx.Model(&models.Lesson{}).Select("room_key").
+		Where("generation_id = (?) AND room_key <> ''", activeGeneration(tx))
+}
+
+// ListRooms returns the rooms used in the live schedule.
+func ListRooms(ctx context.Context) ([]models.Room, error) {
+	var rooms []models.Room
+	if err := DB.WithContext(ctx).Where("key IN (?)", liveRoomKeys(DB)).Order("building, number").Find(&rooms).Error; err != nil {
+		return nil, err
+	}
+	return rooms, nil
+}
+
+func GetRoom(ctx context.Context, id uint) (*models.Room, error) {
+	var r models.Room
+	if err := DB.WithContext(ctx).First(&r, id).Error; err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// GetRoomLessons returns the lessons held in the room in the live schedule.
+func GetRoomLessons(ctx context.Context, key string) ([]models.Lesson, error) {
+	var lessons []models.Lesson
+	if err := DB.WithContext(ctx).
+		Where("generation_id = (?) AND room_key = ?", activeGeneration(DB), key).
+		Find(&lessons).Error; err != nil {
+		return nil, err
+	}
+	return lessons, nil
+}
+
+// ListFreeRooms returns the rooms of the live schedule that have no lesson on
+// day overlapping start to end ("15:04:05"). Cancelled lessons leave their
+// room free.
+func ListFreeRooms(ctx context.Context, day int, start, end string) ([]models.Room, error) {
+	busy := liveRoomKeys(DB).
+		Where("lesson_day = ? AND lesson_start < ? AND lesson_end > ?", day, end, start).
+		Where("COALESCE(status, '') <> ?", models.LessonCancelled)
+
+	var rooms []models.Room
+	if err := DB.WithContext(ctx).
+		Where("key IN (?) AND key NOT IN (?)", liveRoomKeys(DB), busy).
+		Order("building, number").
+		Find(&rooms).Error; err != nil {
+		return nil, err
+	}
+	return rooms, nil
+}
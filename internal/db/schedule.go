@@ -0,0 +1,294 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/in-nis/cnis-back/internal/models"
+)
+
+// generationsToKeep is how many published generations survive a new import.
+const generationsToKeep = 5
+
+var ErrNoPreviousGeneration = errors.New("no previous schedule generation")
+
+// generationLock is the key of the Postgres advisory lock held by every
+// transaction that changes which generation is live.
+const generationLock = 0x636e6973 // "cnis"
+
+// activeGeneration is a subquery selecting the ID of the live generation.
+func activeGeneration(tx *gorm.DB) *gorm.DB {
+	return tx.Model(&models.ScheduleGeneration{}).Select("id").Where("active = ?", true).Order("id DESC").Limit(1)
+}
+
+// lockGenerations serializes publish, rollback and activate across server
+// instances until tx ends.
+func lockGenerations(tx *gorm.DB) error {
+	return tx.Exec("SELECT pg_advisory_xact_lock(?)", generationLock).Error
+}
+
+// PublishGeneration stores lessons as a new generation and makes it the live
+// schedule, saving the teachers and rooms they refer to (see excel.Teachers
+// and excel.Rooms). Everything happens in one transaction, so readers see
+// either the old timetable or the complete new one.
+func PublishGeneration(ctx context.Context, source string, lessons []models.Lesson, teachers []models.Teacher, rooms []models.Room) (*models.ScheduleGeneration, error) {
+	var gen models.ScheduleGeneration
+
+	err := DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockGenerations(tx); err != nil {
+			return err
+		}
+
+		gen = models.ScheduleGeneration{Source: source, LessonCount: len(lessons)}
+		if err := tx.Create(&gen).Error; err != nil {
+			return err
+		}
+
+		for i := range lessons {
+			lessons[i].ID = 0
+			lessons[i].GenerationID = gen.ID
+		}
+		if len(lessons) > 0 {
+			if err := tx.CreateInBatches(&lessons, 500).Error; err != nil {
+				return err
+			}
+		}
+		if err := saveTeachers(tx, teachers); err != nil {
+			return err
+		}
+		if err := saveRooms(tx, rooms); err != nil {
+			return err
+		}
+
+		if err := activate(tx, &gen); err != nil {
+			return err
+		}
+
+		return prune(tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &gen, nil
+}
+
+// adoptLegacyLessons puts lessons stored before schedule generations existed
+// into a generation of their own, so they stay visible until the next import.
+// It is made live unless another generation already is.
+func adoptLegacyLessons() error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockGenerations(tx); err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&models.Lesson{}).Where("generation_id IS NULL OR generation_id = 0").Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return nil
+		}
+
+		gen := models.ScheduleGeneration{Source: "legacy", LessonCount: int(count)}
+		if err := tx.Create(&gen).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Lesson{}).
+			Where("generation_id IS NULL OR generation_id = 0").
+			Update("generation_id", gen.ID).Error; err != nil {
+			return err
+		}
+
+		var active int64
+		if err := tx.Model(&models.ScheduleGeneration{}).Where("active = ?", true).Count(&active).Error; err != nil {
+			return err
+		}
+		if active == 0 {
+			if err := activate(tx, &gen); err != nil {
+				return err
+			}
+		}
+
+		log.Printf("📦 Adopted %d existing lessons as schedule generation #%d\n", count, gen.ID)
+		return nil
+	})
+}
+
+// RollbackGeneration re-activates the generation published before the live one.
+func RollbackGeneration(ctx context.Context) (*models.ScheduleGeneration, error) {
+	var prev models.ScheduleGeneration
+
+	err := DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockGenerations(tx); err != nil {
+			return err
+		}
+
+		var current models.ScheduleGeneration
+		if err := tx.Where("active = ?", true).First(&current).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("id < ? AND published_at IS NOT NULL", current.ID).Order("id DESC").First(&prev).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNoPreviousGeneration
+			}
+			return err
+		}
+
+		return activate(tx, &prev)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &prev, nil
+}
+
+// ActivateGeneration makes the generation with the given ID the live schedule.
+func ActivateGeneration(ctx context.Context, id uint) (*models.ScheduleGeneration, error) {
+	var gen models.ScheduleGeneration
+
+	err := DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockGenerations(tx); err != nil {
+			return err
+		}
+		if err := tx.First(&gen, id).Error; err != nil {
+			return err
+		}
+		return activate(tx, &gen)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &gen, nil
+}
+
+// GetActiveLessons returns every lesson of the live generation.
+func GetActiveLessons(ctx context.Context) ([]models.Lesson, error) {
+	var lessons []models.Lesson
+	if err := DB.WithContext(ctx).Where("generation_id = (?)", activeGeneration(DB)).Find(&lessons).Error; err != nil {
+		return nil, err
+	}
+	return lessons, nil
+}
+
+func ListGenerations(ctx context.Context) ([]models.ScheduleGeneration, error) {
+	var gens []models.ScheduleGeneration
+	if err := DB.WithContext(ctx).Order("id DESC").Find(&gens).Error; err != nil {
+		return nil, err
+	}
+	return gens, nil
+}
+
+func activate(tx *gorm.DB, gen *models.ScheduleGeneration) error {
+	if err := tx.Model(&models.ScheduleGeneration{}).
+		Where("active = ? AND id <> ?", true, gen.ID).
+		Update("active", false).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if gen.PublishedAt == nil {
+		gen.PublishedAt = &now
+	}
+	gen.Active = true
+	return tx.Model(gen).Updates(map[string]interface{}{"active": true, "published_at": gen.PublishedAt}).Error
+}
+
+// prune drops lessons and generations older than the last generationsToKeep.
+func prune(tx *gorm.DB) error {
+	var stale []uint
+	if err := tx.Model(&models.ScheduleGeneration{}).
+		Where("active = ?", false).
+		Order("id DESC").
+		Offset(generationsToKeep-1).
+		Pluck("id", &stale).Error; err != nil {
+		return err
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+
+	if err := tx.Where("generation_id IN ?", stale).Delete(&models.Lesson{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("id IN ?", stale).Delete(&models.ScheduleGeneration{}).Error
+}
+
+func SaveImportReport(ctx context.Context, r *models.ImportReport) error {
+	return DB.WithContext(ctx).Create(r).Error
+}
+
+// GetLatestImportReport returns the report of the most recent import attempt.
+func GetLatestImportReport(ctx context.Context) (*models.ImportReport, error) {
+	var r models.ImportReport
+	if err := DB.WithContext(ctx).Order("id DESC").First(&r).Error; err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func SaveImportLog(ctx context.Context, l *models.ImportLog) error {
+	return DB.WithContext(ctx).Create(l).Error
+}
+
+// GetLastImported returns the latest log entry for source whose workbook is
+// the live one (imported, or found unchanged since), or nil if there is none.
+func GetLastImported(ctx context.Context, source string) (*models.ImportLog, error) {
+	var l models.ImportLog
+	err := DB.WithContext(ctx).
+		Where("source = ? AND status IN ?", source, []string{models.ImportImported, models.ImportUnchanged}).
+		Order("id DESC").
+		First(&l).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func ListImportLogs(ctx context.Context, limit int) ([]models.ImportLog, error) {
+	var logs []models.ImportLog
+	if err := DB.WithContext(ctx).Order("id DESC").Limit(limit).Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+func ListParallelSettings(ctx context.Context) ([]models.ParallelSetting, error) {
+	var settings []models.ParallelSetting
+	if err := DB.WithContext(ctx).Order("grade").Find(&settings).Error; err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// GetDisabledParallels returns the set of grades switched off by an admin.
+func GetDisabledParallels(ctx context.Context) (map[int]bool, error) {
+	var grades []int
+	if err := DB.WithContext(ctx).Model(&models.ParallelSetting{}).
+		Where("enabled = ?", false).
+		Pluck("grade", &grades).Error; err != nil {
+		return nil, err
+	}
+
+	disabled := make(map[int]bool, len(grades))
+	for _, g := range grades {
+		disabled[g] = true
+	}
+	return disabled, nil
+}
+
+func SetParallelEnabled(ctx context.Context, grade int, enabled bool) error {
+	setting := models.ParallelSetting{Grade: grade, Enabled: enabled}
+	return DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "grade"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "updated_at"}),
+	}).Create(&setting).Error
+}
@@ -0,0 +1,54 @@
+package db
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/in-nis/cnis-back/internal/models"
+)
+
+// saveTeachers creates or updates the teachers of a new generation. A known
+// teacher's name is only replaced by a longer, fuller spelling.
+func saveTeachers(tx *gorm.DB, teachers []models.Teacher) error {
+	if len(teachers) == 0 {
+		return nil
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "key"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"name":       gorm.Expr("CASE WHEN length(excluded.name) > length(teachers.name) THEN excluded.name ELSE teachers.name END"),
+			"updated_at": gorm.Expr("excluded.updated_at"),
+		}),
+	}).CreateInBatches(&teachers, 500).Error
+}
+
+// ListTeachers returns the teachers who have lessons in the live schedule.
+func ListTeachers(ctx context.Context) ([]models.Teacher, error) {
+	var teachers []models.Teacher
+	live := DB.Model(&models.Lesson{}).Select("teacher_key").Where("generation_id = (?)", activeGeneration(DB))
+	if err := DB.WithContext(ctx).Where("key IN (?)", live).Order("name").Find(&teachers).Error; err != nil {
+		return nil, err
+	}
+	return teachers, nil
+}
+
+func GetTeacher(ctx context.Context, id uint) (*models.Teacher, error) {
+	var t models.Teacher
+	if err := DB.WithContext(ctx).First(&t, id).Error; err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// GetTeacherLessons returns the teacher's lessons in the live schedule.
+func GetTeacherLessons(ctx context.Context, key string) ([]models.Lesson, error) {
+	var lessons []models.Lesson
+	if err := DB.WithContext(ctx).
+		Where("generation_id = (?) AND teacher_key = ?", activeGeneration(DB), key).
+		Find(&lessons).Error; err != nil {
+		return nil, err
+	}
+	return lessons, nil
+}
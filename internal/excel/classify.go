@@ -0,0 +1,68 @@
+package excel
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// What a detail line of a lesson cell looks like.
+type lineKind int
+
+const (
+	kindUnknown lineKind = iota
+	kindTeacher
+	kindRoom
+)
+
+func (k lineKind) String() string {
+	switch k {
+	case kindTeacher:
+		return LineTeacher
+	case kindRoom:
+		return LineRoom
+	}
+	return "unknown"
+}
+
+// roomWords start a room line: "каб. 301", "ауд 12", "корпус 2, 105",
+// "Спортзал", "Актовый зал".
+var roomWords = []string{
+	"каб", "кабинет", "ауд", "аудитория", "корп", "корпус", "блок", "лаб",
+	"лаборатория", "зал", "спортзал", "актовый", "библиотека", "мастерская",
+	"бөлме", "дәрісхана", "room", "rm", "lab", "gym", "hall", "library",
+}
+
+var (
+	// "301", "301а", "Б-204", "A 12", "2.105"
+	roomNumber = regexp.MustCompile(`^\p{L}{0,3}\s*[-.]?\s*\d{1,4}(\s*[-./]\s*\d{1,4})?\s*\p{L}?$`)
+	// "Иванов И.И.", "Smith J.", "Абаев А"
+	surnameInitials = regexp.MustCompile(`^\p{Lu}[\p{L}'’-]+\s+\p{Lu}\.?\s*(\p{Lu}\.?)?$`)
+	// "И.И. Иванов", "J. Smith"
+	initialsSurname = regexp.MustCompile(`^\p{Lu}\.\s*(\p{Lu}\.\s*)?\p{Lu}[\p{L}'’-]+$`)
+	// "Иванов Иван Иванович", "Абаев Асан Серікұлы"
+	fullName = regexp.MustCompile(`^\p{Lu}\p{Ll}+\s+\p{Lu}\p{Ll}+\s+\p{Lu}\p{Ll}+(ович|евич|ич|овна|евна|ична|инична|ұлы|улы|қызы|кызы)$`)
+)
+
+// classifyLine guesses whether a detail line names a teacher or a room.
+func classifyLine(line string) lineKind {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return kindUnknown
+	}
+
+	if ws := words(line); len(ws) > 0 && containsString(roomWords, ws[0]) {
+		return kindRoom
+	}
+	if roomNumber.MatchString(line) {
+		return kindRoom
+	}
+
+	if strings.IndexFunc(line, unicode.IsDigit) >= 0 {
+		return kindUnknown
+	}
+	if surnameInitials.MatchString(line) || initialsSurname.MatchString(line) || fullName.MatchString(line) {
+		return kindTeacher
+	}
+	return kindUnknown
+}
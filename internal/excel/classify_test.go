@@ -0,0 +1,113 @@
+package excel
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClassifyLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want lineKind
+	}{
+		{"Иванов И.И.", kindTeacher},
+		{"Абаев А", kindTeacher},
+		{"Smith J.", kindTeacher},
+		{"J. Smith", kindTeacher},
+		{"И.И. Иванов", kindTeacher},
+		{"Иванов Иван Иванович", kindTeacher},
+		{"Абаев Асан Серікұлы", kindTeacher},
+		{"каб. 301", kindRoom},
+		{"Кабинет 12", kindRoom},
+		{"301", kindRoom},
+		{"301а", kindRoom},
+		{"Б-204", kindRoom},
+		{"2.105", kindRoom},
+		{"Спортзал", kindRoom},
+		{"Актовый зал", kindRoom},
+		{"Gym", kindRoom},
+		{"", kindUnknown},
+		{"по подгруппам", kindUnknown},
+		{"Иванов 2", kindUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := classifyLine(tt.line); got != tt.want {
+			t.Errorf("classifyLine(%q) = %s, want %s", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestCellDetails(t *testing.T) {
+	tests := []struct {
+		name, value           string
+		lesson, teacher, room string
+		uncertain             int
+	}{
+		{
+			name:   "teacher then room",
+			value:  "Физика\nИванов И.И.\n301",
+			lesson: "Физика", teacher: "Иванов И.И.", room: "301",
+		},
+		{
+			name:   "room then teacher",
+			value:  "Биология\nкаб. 301\nПетрова А.А.",
+			lesson: "Биология", teacher: "Петрова А.А.", room: "каб. 301",
+		},
+		{
+			name:   "room only",
+			value:  "Физкультура\nСпортзал",
+			lesson: "Физкультура", room: "Спортзал",
+		},
+		{
+			name:   "unclassified lines fall back to their roles",
+			value:  "Классный час\nкураторы\nпо графику",
+			lesson: "Классный час", teacher: "кураторы", room: "по графику",
+			uncertain: 2,
+		},
+		{
+			name:   "second teacher ignored",
+			value:  "Англ\nSmith J.\nBrown K.",
+			lesson: "Англ", teacher: "Smith J.",
+			uncertain: 1,
+		},
+	}
+
+	l := DefaultLayout()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lesson, teacher, room, uncertain := l.cellDetails(tt.value)
+			if lesson != tt.lesson || teacher != tt.teacher || room != tt.room {
+				t.Errorf("cellDetails(%q) = %q, %q, %q, want %q, %q, %q",
+					tt.value, lesson, teacher, room, tt.lesson, tt.teacher, tt.room)
+			}
+			if len(uncertain) != tt.uncertain {
+				t.Errorf("uncertain = %q, want %d entries", uncertain, tt.uncertain)
+			}
+		})
+	}
+}
+
+func TestParseSwappedTeacherAndRoom(t *testing.T) {
+	lessons, _ := mustParse(t, nil, testSheet{
+		name: "12 пн",
+		cells: map[string]any{
+			"C1": "12A", "D1": "12B",
+			"B2": "08:00-08:45", "C2": "Биология\nкаб. 301\nПетрова А.А.", "D2": "Англ №1 / Англ №2\nSmith J.\n204 / 205",
+		},
+	})
+
+	if l := findLesson(t, lessons, "12A", "Биология"); l.LessonTeacher != "Петрова А.А." || l.LessonClass != "каб. 301" {
+		t.Errorf("Биология: teacher %q, room %q", l.LessonTeacher, l.LessonClass)
+	}
+	// Group lessons belong to the whole parallel, so they have no letter.
+	rooms := make(map[string]string)
+	for _, l := range lessons {
+		if l.LessonName == "Англ" && l.LessonTeacher == "Smith J." {
+			rooms[l.LessonGroup] = l.LessonClass
+		}
+	}
+	if want := map[string]string{"№1": "204", "№2": "205"}; !reflect.DeepEqual(rooms, want) {
+		t.Errorf("Англ rooms by group = %v, want %v", rooms, want)
+	}
+}
@@ -0,0 +1,52 @@
+package excel
+
+import "github.com/xuri/excelize/v2"
+
+// resolveDays works out the weekday of every row below the header according
+// to the layout's DaySource. It reports whether any row got a day at all.
+func (sp *sheetParser) resolveDays(rows []sheetRow) bool {
+	sp.days = make(map[int]int)
+	mode := sp.layout.DaySource
+	current := 0
+
+	if mode == DayAuto || mode == DayFromCell {
+		var locale string
+		if current, locale = sp.layout.parseDay(sp.dayCell); current != 0 {
+			sp.report.noteLocale(sp.sheet, locale)
+			sp.report.info(sp.sheet, sp.layout.DayCell, sp.dayCell, "day %d taken from day cell (locale %s)", current, locale)
+		} else if mode == DayFromCell {
+			sp.report.problem(ErrUnknownDay, SeverityError, sp.sheet, sp.layout.DayCell, sp.dayCell, "unknown day")
+		}
+	}
+
+	if current == 0 && (mode == DayAuto || mode == DayFromSheetName) {
+		var locale string
+		if current, locale = sp.layout.parseDay(sp.sheet); current != 0 {
+			sp.report.noteLocale(sp.sheet, locale)
+			sp.report.info(sp.sheet, "", sp.sheet, "day %d taken from sheet name (locale %s)", current, locale)
+		} else if mode == DayFromSheetName {
+			sp.report.problem(ErrUnknownDay, SeverityError, sp.sheet, "", sp.sheet, "sheet name has no day")
+		}
+	}
+
+	found := false
+	for _, row := range rows {
+		if label := row.dayLabel; label != "" && (mode == DayAuto || mode == DayFromColumn) {
+			axis, _ := excelize.CoordinatesToCellName(sp.layout.dayColumn, row.num)
+			if day, locale := sp.layout.parseDay(label); day != 0 {
+				sp.report.noteLocale(sp.sheet, locale)
+				if day != current {
+					sp.report.info(sp.sheet, axis, label, "day %d starts here (locale %s)", day, locale)
+				}
+				current = day
+			} else if mode == DayFromColumn {
+				sp.report.problem(ErrUnknownDay, SeverityWarning, sp.sheet, axis, label, "unknown day label")
+			}
+		}
+
+		sp.days[row.num] = current
+		found = found || current != 0
+	}
+
+	return found
+}
@@ -0,0 +1,100 @@
+package excel
+
+import (
+	"testing"
+)
+
+func TestResolveDaysFromSheetName(t *testing.T) {
+	lessons, _ := mustParse(t, nil, testSheet{
+		name: "11 вт",
+		cells: map[string]any{
+			"C1": "11А",
+			"B2": "08:00-08:45", "C2": "История\nАбаев А.\n12",
+		},
+	})
+
+	if l := findLesson(t, lessons, "11А", "История"); l.LessonDay != 2 {
+		t.Errorf("lesson on day %d, want 2 from the sheet name", l.LessonDay)
+	}
+}
+
+func TestResolveDaysDayCellWins(t *testing.T) {
+	lessons, _ := mustParse(t, nil, testSheet{
+		name: "11 вт",
+		cells: map[string]any{
+			"A1": "Четверг", "C1": "11А",
+			"B2": "08:00-08:45", "C2": "История\nАбаев А.\n12",
+		},
+	})
+
+	if l := findLesson(t, lessons, "11А", "История"); l.LessonDay != 4 {
+		t.Errorf("lesson on day %d, want 4 from the day cell", l.LessonDay)
+	}
+}
+
+func TestResolveDaysStacked(t *testing.T) {
+	lessons, _ := mustParse(t, nil, testSheet{
+		name: "10 классы",
+		cells: map[string]any{
+			"C1": "10A",
+			"A2": "Понедельник", "B2": "08:00-08:45", "C2": "Биология\nА\n1",
+			"B3": "08:50-09:35", "C3": "География\nБ\n2",
+			"A4": "Вторник", "B4": "08:00-08:45", "C4": "Химия\nВ\n3",
+		},
+		merges: [][2]string{{"A2", "A3"}},
+	})
+
+	tests := []struct {
+		name        string
+		day, period int
+	}{
+		{"Биология", 1, 1},
+		{"География", 1, 2},
+		{"Химия", 2, 1}, // periods restart with the day
+	}
+	for _, tt := range tests {
+		l := findLesson(t, lessons, "10A", tt.name)
+		if l.LessonDay != tt.day || l.Period != tt.period {
+			t.Errorf("%s on day %d period %d, want day %d period %d", tt.name, l.LessonDay, l.Period, tt.day, tt.period)
+		}
+	}
+}
+
+func TestResolveDaysUnknownDay(t *testing.T) {
+	layout := testLayout(t, func(l *Layout) { l.DaySource = DayFromSheetName })
+	lessons, report := mustParse(t, NewParser(layout),
+		testSheet{
+			name: "9",
+			cells: map[string]any{
+				"C1": "9A",
+				"B2": "08:00-08:45", "C2": "Физика\nИванов И.И.\n301",
+			},
+		},
+		testSheet{
+			name: "10 Пятница",
+			cells: map[string]any{
+				"C1": "10A",
+				"B2": "08:00-08:45", "C2": "Физика\nИванов И.И.\n301",
+			},
+		},
+	)
+
+	for _, l := range lessons {
+		if l.Grade == 9 {
+			t.Errorf("sheet without a day produced %+v", l)
+		}
+	}
+	if l := findLesson(t, lessons, "10A", "Физика"); l.LessonDay != 5 {
+		t.Errorf("lesson on day %d, want 5", l.LessonDay)
+	}
+
+	found := false
+	for _, d := range report.Diagnostics {
+		if d.Sheet == "9" && d.Code == "unknown_day" && d.Severity == SeverityError {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("no unknown_day error for sheet 9 in %+v", report.Diagnostics)
+	}
+}
@@ -0,0 +1,87 @@
+package excel
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// detectRows is how many rows at the top of a sheet the detector looks at.
+const detectRows = 20
+
+// SheetLayout is where the parser found the header row and time column of a
+// sheet, and whether they were detected or taken from the layout.
+type SheetLayout struct {
+	HeaderRow          int    `json:"header_row"`
+	HeaderRowDetected  bool   `json:"header_row_detected"`
+	TimeColumn         string `json:"time_column"`
+	TimeColumnDetected bool   `json:"time_column_detected"`
+}
+
+// detect looks at the top rows of the sheet for the row with the most grade
+// headers and, left of the first header, the column with the most time
+// ranges. What it finds overrides the layout for this sheet only.
+func (sp *sheetParser) detect(top [][]string) {
+	result := SheetLayout{HeaderRow: sp.layout.HeaderRow, TimeColumn: sp.layout.TimeColumn}
+	defer func() { sp.report.Layouts[sp.sheet] = result }()
+
+	headerRow, headers, firstHeaderCol := 0, 0, 0
+	for i, cols := range top {
+		n, first := 0, 0
+		for j, v := range cols {
+			if _, _, _, ok := sp.layout.parseGradeHeader(v); ok {
+				n++
+				if first == 0 {
+					first = j + 1
+				}
+			}
+		}
+		if n > headers {
+			headerRow, headers, firstHeaderCol = i+1, n, first
+		}
+	}
+	if headerRow != 0 && headerRow != sp.layout.HeaderRow {
+		sp.layout.HeaderRow = headerRow
+		result.HeaderRow, result.HeaderRowDetected = headerRow, true
+		sp.report.info(sp.sheet, "", "", "header row %d detected (%d grade headers)", headerRow, headers)
+	}
+
+	counts := make(map[int]int)
+	for i := sp.layout.HeaderRow; i < len(top); i++ {
+		for j, v := range top[i] {
+			if firstHeaderCol != 0 && j+1 >= firstHeaderCol {
+				break
+			}
+			if looksLikeTime(v) {
+				counts[j+1]++
+			}
+		}
+	}
+	timeCol, ranges := 0, 0
+	for col, n := range counts {
+		if n > ranges || (n == ranges && col < timeCol) {
+			timeCol, ranges = col, n
+		}
+	}
+	if ranges >= 2 && timeCol != sp.layout.timeColumn {
+		name, _ := excelize.ColumnNumberToName(timeCol)
+		sp.layout.timeColumn, sp.layout.TimeColumn = timeCol, name
+		result.TimeColumn, result.TimeColumnDetected = name, true
+		sp.report.info(sp.sheet, "", "", "time column %s detected (%d time ranges)", name, ranges)
+	}
+}
+
+// looksLikeTime reports whether a raw cell value is a time range or an
+// Excel time. A lone "8.30" is not enough, since rooms look like that too.
+func looksLikeTime(v string) bool {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return false
+	}
+	if _, _, hasEnd, err := parseTimeRange(v); err == nil && hasEnd {
+		return true
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	return err == nil && f > 0 && f < 1
+}
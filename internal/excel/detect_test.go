@@ -0,0 +1,82 @@
+package excel
+
+import "testing"
+
+func TestDetectTitleRowOffset(t *testing.T) {
+	lessons, report := mustParse(t, nil, testSheet{
+		name: "8 ср",
+		cells: map[string]any{
+			"A1": "Расписание уроков",
+			"A2": "logo",
+			"D3": "8A", "E3": "8 Б класс",
+			"B4": 1, "C4": "08:00-08:45", "D4": "Алгебра\nКим К.К.\n210",
+			"B5": 2, "C5": "08:50-09:35", "E5": "Физика\nИванов И.И.\n301",
+		},
+	})
+
+	want := SheetLayout{HeaderRow: 3, HeaderRowDetected: true, TimeColumn: "C", TimeColumnDetected: true}
+	if got := report.Layouts["8 ср"]; got != want {
+		t.Errorf("layout = %+v, want %+v", got, want)
+	}
+
+	if l := findLesson(t, lessons, "8A", "Алгебра"); l.LessonDay != 3 || l.LessonStart.Format("15:04") != "08:00" {
+		t.Errorf("Алгебра on day %d at %s, want day 3 at 08:00", l.LessonDay, l.LessonStart.Format("15:04"))
+	}
+	if l := findLesson(t, lessons, "8Б", "Физика"); l.LessonStart.Format("15:04") != "08:50" {
+		t.Errorf("Физика at %s, want 08:50", l.LessonStart.Format("15:04"))
+	}
+}
+
+func TestDetectKazakhHeaders(t *testing.T) {
+	lessons, report := mustParse(t, nil, testSheet{
+		name: "10 дүйсенбі",
+		cells: map[string]any{
+			"A1": "Сабақ кестесі",
+			"D2": "10 «А» сынып", "E2": "10 «Ә» сынып",
+			"C3": "08:00-08:45", "D3": "Алгебра\nКим К.К.\n210", "E3": "Қазақ тілі\nАбаев А.\n211",
+			"C4": "08:50-09:35", "D4": "Физика\nИванов И.И.\n301",
+		},
+	})
+
+	want := SheetLayout{HeaderRow: 2, HeaderRowDetected: true, TimeColumn: "C", TimeColumnDetected: true}
+	if got := report.Layouts["10 дүйсенбі"]; got != want {
+		t.Errorf("layout = %+v, want %+v", got, want)
+	}
+	if l := findLesson(t, lessons, "10Ә", "Қазақ тілі"); l.LessonDay != 1 {
+		t.Errorf("lesson on day %d, want 1", l.LessonDay)
+	}
+}
+
+func TestDetectOff(t *testing.T) {
+	layout := testLayout(t, func(l *Layout) { l.AutoDetect = false })
+	lessons, report := mustParse(t, NewParser(layout), testSheet{
+		name: "12 пн",
+		cells: map[string]any{
+			"C1": "12A",
+			"B2": "08:00-08:45", "C2": "Физика\nИванов И.И.\n301",
+		},
+	})
+
+	if got, ok := report.Layouts["12 пн"]; ok {
+		t.Errorf("layout = %+v, want none with detection off", got)
+	}
+	if l := findLesson(t, lessons, "12A", "Физика"); l.LessonStart.Format("15:04") != "08:00" {
+		t.Errorf("Физика at %s, want 08:00", l.LessonStart.Format("15:04"))
+	}
+}
+
+func TestDetectSingleTimeRow(t *testing.T) {
+	_, report := mustParse(t, nil, testSheet{
+		name: "12 пн",
+		cells: map[string]any{
+			"C1": "12A",
+			"B2": "08:00-08:45", "C2": "Физика\nИванов И.И.\n301",
+		},
+	})
+
+	// One time range is not enough to move the time column.
+	want := SheetLayout{HeaderRow: 1, TimeColumn: "B"}
+	if got := report.Layouts["12 пн"]; got != want {
+		t.Errorf("layout = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,81 @@
+package excel
+
+import (
+	"sort"
+
+	"github.com/in-nis/cnis-back/internal/models"
+)
+
+// LessonChange pairs a live lesson with its replacement from a new workbook.
+type LessonChange struct {
+	Before models.Lesson `json:"before"`
+	After  models.Lesson `json:"after"`
+}
+
+// ScheduleDiff is what publishing a workbook would do to the live schedule.
+type ScheduleDiff struct {
+	Added   []models.Lesson `json:"added"`
+	Removed []models.Lesson `json:"removed"`
+	Changed []LessonChange  `json:"changed"`
+}
+
+// lessonIdentity is what makes two lessons "the same lesson" across imports.
+// Rows stored before keys existed get theirs computed on the fly.
+func lessonIdentity(l models.Lesson) string {
+	if l.LessonKey != "" {
+		return l.LessonKey
+	}
+	return LessonKey(l)
+}
+
+func sameDetails(a, b models.Lesson) bool {
+	return a.LessonStart.Format("15:04") == b.LessonStart.Format("15:04") &&
+		a.LessonEnd.Format("15:04") == b.LessonEnd.Format("15:04") &&
+		a.LessonTeacher == b.LessonTeacher &&
+		a.LessonClass == b.LessonClass &&
+		a.Status == b.Status &&
+		a.Format == b.Format &&
+		a.Building == b.Building
+}
+
+// Diff compares the live lessons with freshly parsed ones.
+func Diff(live, incoming []models.Lesson) ScheduleDiff {
+	diff := ScheduleDiff{
+		Added:   []models.Lesson{},
+		Removed: []models.Lesson{},
+		Changed: []LessonChange{},
+	}
+
+	liveByKey := make(map[string]models.Lesson, len(live))
+	for _, l := range live {
+		liveByKey[lessonIdentity(l)] = l
+	}
+
+	seen := make(map[string]bool, len(incoming))
+	for _, l := range incoming {
+		key := lessonIdentity(l)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		before, ok := liveByKey[key]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, l)
+		case !sameDetails(before, l):
+			diff.Changed = append(diff.Changed, LessonChange{Before: before, After: l})
+		}
+	}
+
+	for key, l := range liveByKey {
+		if !seen[key] {
+			diff.Removed = append(diff.Removed, l)
+		}
+	}
+	sort.Slice(diff.Removed, func(i, j int) bool {
+		return lessonIdentity(diff.Removed[i]) < lessonIdentity(diff.Removed[j])
+	})
+
+	return diff
+}
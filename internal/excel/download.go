@@ -0,0 +1,253 @@
+package excel
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var baseUrl = "https://docs.google.com/spreadsheets/d/1KbzUHfsSwywWOzswZzdtIcdWzeZUNsI1/export?format=xlsx&id=1KbzUHfsSwywWOzswZzdtIcdWzeZUNsI1"
+
+// -------------------- DOWNLOAD --------------------
+
+// FetchState is what we remember about the last downloaded workbook.
+type FetchState struct {
+	ETag         string
+	LastModified string
+	ContentHash  string // hex SHA-256 of the workbook bytes
+}
+
+var (
+	// ErrNotModified means the workbook is the same as the one described by
+	// the previous FetchState, so there is nothing to import.
+	ErrNotModified = errors.New("schedule workbook not modified")
+	// ErrTooLarge means the response body exceeded Downloader.MaxBytes.
+	ErrTooLarge = errors.New("schedule workbook too large")
+	// ErrNotWorkbook means the response is not an xlsx file, e.g. a login page.
+	ErrNotWorkbook = errors.New("response is not an xlsx workbook")
+)
+
+// xlsx files are zip archives
+var zipSignature = []byte("PK\x03\x04")
+
+// Downloader fetches the timetable workbook over HTTP.
+type Downloader struct {
+	Client   *http.Client
+	Timeout  time.Duration // per attempt
+	Retries  int           // extra attempts after the first one
+	Backoff  time.Duration // wait before the first retry, doubled each time
+	MaxBytes int64
+}
+
+// NewDownloader returns a downloader with conservative defaults.
+func NewDownloader() *Downloader {
+	return &Downloader{
+		Client:   &http.Client{},
+		Timeout:  30 * time.Second,
+		Retries:  3,
+		Backoff:  time.Second,
+		MaxBytes: 20 << 20,
+	}
+}
+
+var defaultDownloader = NewDownloader()
+
+// GetExcel downloads the workbook at url into filePath with the default
+// downloader. See Downloader.Download.
+func GetExcel(ctx context.Context, url, filePath string, prev FetchState) (FetchState, error) {
+	return defaultDownloader.Download(ctx, url, filePath, prev)
+}
+
+// Download fetches the workbook at url into filePath. An empty url falls back
+// to the school's published export. The request is conditional on prev; if
+// the server answers 304, or sends back bytes with the same hash, the file is
+// left alone and ErrNotModified is returned.
+//
+// The body goes to a temp file next to filePath that is renamed into place
+// only once it is complete, so an interrupted download never leaves a
+// truncated workbook behind.
+func (d *Downloader) Download(ctx context.Context, url, filePath string, prev FetchState) (FetchState, error) {
+	if url == "" {
+		url = baseUrl
+	}
+
+	backoff := d.Backoff
+	var err error
+	for attempt := 0; ; attempt++ {
+		log.Println("📥 Downloading Excel from:", url)
+
+		var state FetchState
+		var retry bool
+		state, retry, err = d.attempt(ctx, url, filePath, prev)
+		if err == nil || errors.Is(err, ErrNotModified) {
+			return state, err
+		}
+		if !retry || attempt >= d.Retries {
+			break
+		}
+
+		log.Printf("⚠️ Download attempt %d failed: %v; retrying in %s\n", attempt+1, err, backoff)
+		select {
+		case <-ctx.Done():
+			return prev, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	return prev, err
+}
+
+// attempt makes one request. retry reports whether the failure is worth
+// another try.
+func (d *Downloader) attempt(ctx context.Context, url, filePath string, prev FetchState) (state FetchState, retry bool, err error) {
+	if d.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.Timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return prev, false, fmt.Errorf("failed to build request: %w", err)
+	}
+	if prev.ETag != "" {
+		req.Header.Set("If-None-Match", prev.ETag)
+	}
+	if prev.LastModified != "" {
+		req.Header.Set("If-Modified-Since", prev.LastModified)
+	}
+
+	client := d.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return prev, true, fmt.Errorf("failed to fetch excel: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified:
+		log.Println("⏸️ Excel not modified since last download")
+		return prev, false, ErrNotModified
+	case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode >= 500:
+		return prev, true, fmt.Errorf("bad status: %s", resp.Status)
+	case resp.StatusCode != http.StatusOK:
+		return prev, false, fmt.Errorf("bad status: %s", resp.Status)
+	}
+
+	if err := checkContentType(resp.Header.Get("Content-Type")); err != nil {
+		return prev, false, err
+	}
+	if d.MaxBytes > 0 && resp.ContentLength > d.MaxBytes {
+		return prev, false, fmt.Errorf("%w: %d bytes", ErrTooLarge, resp.ContentLength)
+	}
+
+	state = FetchState{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+	state.ContentHash, err = d.save(resp.Body, filePath, prev.ContentHash)
+	if err != nil {
+		var netErr interface{ Timeout() bool }
+		retry = errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
+		return prev, retry, err
+	}
+	if prev.ContentHash != "" && state.ContentHash == prev.ContentHash {
+		log.Println("⏸️ Excel content unchanged since last download")
+		return state, false, ErrNotModified
+	}
+
+	log.Println("✅ Excel saved to", filePath)
+	return state, false, nil
+}
+
+// save streams body into filePath via a temp file and returns its hash.
+// When the hash equals unchangedHash the temp file is dropped and filePath
+// is left as it was.
+func (d *Downloader) save(body io.Reader, filePath, unchangedHash string) (string, error) {
+	if d.MaxBytes > 0 {
+		body = io.LimitReader(body, d.MaxBytes+1)
+	}
+
+	br := bufio.NewReader(body)
+	head, err := br.Peek(len(zipSignature))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+	if !bytes.Equal(head, zipSignature) {
+		return "", ErrNotWorkbook
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), "."+filepath.Base(filePath)+".*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	hash := sha256.New()
+	n, err := io.Copy(io.MultiWriter(tmp, hash), br)
+	if err != nil {
+		return "", fmt.Errorf("failed to save file: %w", err)
+	}
+	if d.MaxBytes > 0 && n > d.MaxBytes {
+		return "", fmt.Errorf("%w: more than %d bytes", ErrTooLarge, d.MaxBytes)
+	}
+
+	sum := hex.EncodeToString(hash.Sum(nil))
+	if sum == unchangedHash {
+		return sum, nil
+	}
+
+	if err := tmp.Chmod(0o644); err != nil {
+		return "", fmt.Errorf("failed to save file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return "", fmt.Errorf("failed to save file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("failed to save file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		return "", fmt.Errorf("failed to move file into place: %w", err)
+	}
+	return sum, nil
+}
+
+// checkContentType rejects responses that clearly aren't a workbook, such as
+// the HTML sign-in page Google serves for private sheets.
+func checkContentType(contentType string) error {
+	if contentType == "" {
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("%w: bad content type %q", ErrNotWorkbook, contentType)
+	}
+	switch mediaType {
+	case "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		"application/octet-stream",
+		"application/zip",
+		"application/x-zip-compressed":
+		return nil
+	}
+	return fmt.Errorf("%w: content type %s", ErrNotWorkbook, mediaType)
+}
+
+func contentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,246 @@
+package excel
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const xlsxType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// fakeWorkbook returns n bytes that start like an xlsx file.
+func fakeWorkbook(n int) []byte {
+	return append(append([]byte{}, zipSignature...), bytes.Repeat([]byte("x"), n-len(zipSignature))...)
+}
+
+func testDownloader() *Downloader {
+	d := NewDownloader()
+	d.Timeout = 5 * time.Second
+	d.Backoff = 10 * time.Millisecond
+	return d
+}
+
+// tempFile returns a path in a fresh directory holding content, or nothing
+// if content is nil.
+func tempFile(t *testing.T, content []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "sheet.xlsx")
+	if content != nil {
+		if err := os.WriteFile(path, content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return path
+}
+
+// assertFile checks that path holds want and that no temp file was left next to it.
+func assertFile(t *testing.T, path string, want []byte) {
+	t.Helper()
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("file has %d bytes, want %d", len(got), len(want))
+	}
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("directory has %d entries, want only the workbook", len(entries))
+	}
+}
+
+func TestDownloadSavesWorkbook(t *testing.T) {
+	body := fakeWorkbook(1024)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", xlsxType)
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", "Mon, 12 Oct 2026 08:00:00 GMT")
+		w.Write(body)
+	}))
+	defer srv.Close()
+
+	path := tempFile(t, nil)
+	state, err := testDownloader().Download(context.Background(), srv.URL, path, FetchState{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.ETag != `"v1"` || state.LastModified == "" || state.ContentHash != contentHash(body) {
+		t.Errorf("unexpected state %+v", state)
+	}
+	assertFile(t, path, body)
+}
+
+func TestDownloadNotModified(t *testing.T) {
+	old := fakeWorkbook(512)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", xlsxType)
+		w.Write(fakeWorkbook(2048))
+	}))
+	defer srv.Close()
+
+	path := tempFile(t, old)
+	prev := FetchState{ETag: `"v1"`, ContentHash: contentHash(old)}
+	state, err := testDownloader().Download(context.Background(), srv.URL, path, prev)
+	if !errors.Is(err, ErrNotModified) {
+		t.Fatalf("got error %v, want ErrNotModified", err)
+	}
+	if state != prev {
+		t.Errorf("got state %+v, want %+v", state, prev)
+	}
+	assertFile(t, path, old)
+}
+
+func TestDownloadSameContent(t *testing.T) {
+	body := fakeWorkbook(512)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", xlsxType)
+		w.Header().Set("ETag", `"v2"`) // a new ETag for the same bytes
+		w.Write(body)
+	}))
+	defer srv.Close()
+
+	path := tempFile(t, body)
+	_, err := testDownloader().Download(context.Background(), srv.URL, path, FetchState{ETag: `"v1"`, ContentHash: contentHash(body)})
+	if !errors.Is(err, ErrNotModified) {
+		t.Fatalf("got error %v, want ErrNotModified", err)
+	}
+	assertFile(t, path, body)
+}
+
+func TestDownloadRetriesServerErrors(t *testing.T) {
+	body := fakeWorkbook(256)
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= 2 {
+			http.Error(w, "try later", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", xlsxType)
+		w.Write(body)
+	}))
+	defer srv.Close()
+
+	d := testDownloader()
+	d.Retries = 3
+	path := tempFile(t, nil)
+
+	start := time.Now()
+	if _, err := d.Download(context.Background(), srv.URL, path, FetchState{}); err != nil {
+		t.Fatal(err)
+	}
+	if n := calls.Load(); n != 3 {
+		t.Errorf("server got %d requests, want 3", n)
+	}
+	// Waits of 10ms and then 20ms before the second and third attempts.
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("retries took %s, want at least 30ms of backoff", elapsed)
+	}
+	assertFile(t, path, body)
+}
+
+func TestDownloadGivesUpAfterRetries(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, "down", http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	d := testDownloader()
+	d.Retries = 2
+	if _, err := d.Download(context.Background(), srv.URL, tempFile(t, nil), FetchState{}); err == nil {
+		t.Fatal("expected an error")
+	}
+	if n := calls.Load(); n != 3 {
+		t.Errorf("server got %d requests, want 3", n)
+	}
+}
+
+func TestDownloadMaxBytes(t *testing.T) {
+	old := fakeWorkbook(64)
+	for _, declared := range []bool{true, false} {
+		t.Run("content-length="+strconv.FormatBool(declared), func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body := fakeWorkbook(4096)
+				w.Header().Set("Content-Type", xlsxType)
+				if declared {
+					w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+				} else {
+					w.(http.Flusher).Flush() // chunked, so the size is only known while reading
+				}
+				w.Write(body)
+			}))
+			defer srv.Close()
+
+			d := testDownloader()
+			d.MaxBytes = 1024
+			path := tempFile(t, old)
+			if _, err := d.Download(context.Background(), srv.URL, path, FetchState{}); !errors.Is(err, ErrTooLarge) {
+				t.Fatalf("got error %v, want ErrTooLarge", err)
+			}
+			assertFile(t, path, old)
+		})
+	}
+}
+
+func TestDownloadRejectsNonWorkbook(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        []byte
+	}{
+		{"html sign-in page", "text/html; charset=utf-8", []byte("<!DOCTYPE html><html>Sign in</html>")},
+		{"html without content type", "", []byte("<!DOCTYPE html><html>Sign in</html>")},
+		{"not a zip", "application/octet-stream", []byte("plain text, not a workbook")},
+	}
+	old := fakeWorkbook(64)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header()["Content-Type"] = []string{tt.contentType}
+				w.Write(tt.body)
+			}))
+			defer srv.Close()
+
+			path := tempFile(t, old)
+			if _, err := testDownloader().Download(context.Background(), srv.URL, path, FetchState{}); !errors.Is(err, ErrNotWorkbook) {
+				t.Fatalf("got error %v, want ErrNotWorkbook", err)
+			}
+			assertFile(t, path, old)
+		})
+	}
+}
+
+func TestDownloadTruncatedKeepsPreviousFile(t *testing.T) {
+	old := fakeWorkbook(64)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := fakeWorkbook(4096)
+		w.Header().Set("Content-Type", xlsxType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.Write(body[:1000]) // the connection drops mid-body
+	}))
+	defer srv.Close()
+
+	d := testDownloader()
+	d.Retries = 0
+	path := tempFile(t, old)
+	if _, err := d.Download(context.Background(), srv.URL, path, FetchState{}); err == nil {
+		t.Fatal("expected an error")
+	}
+	assertFile(t, path, old)
+}
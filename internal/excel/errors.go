@@ -0,0 +1,101 @@
+package excel
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kinds of parse problems. A ParseError wraps one of them, so callers can
+// test with errors.Is and get the location with errors.As.
+var (
+	ErrUnknownDay         = errors.New("unknown day")
+	ErrBadTimeRange       = errors.New("bad time range")
+	ErrUnknownGradeHeader = errors.New("unknown grade header")
+	ErrEmptyWorkbook      = errors.New("empty workbook")
+)
+
+// errorCodes name the kinds in reports, which outlive the error values.
+var errorCodes = map[error]string{
+	ErrUnknownDay:         "unknown_day",
+	ErrBadTimeRange:       "bad_time_range",
+	ErrUnknownGradeHeader: "unknown_grade_header",
+	ErrEmptyWorkbook:      "empty_workbook",
+}
+
+// ParseError is a problem at a place in the workbook.
+type ParseError struct {
+	Kind   error  `json:"-"`
+	Code   string `json:"code"`
+	Sheet  string `json:"sheet,omitempty"`
+	Axis   string `json:"axis,omitempty"`
+	Value  string `json:"value,omitempty"`
+	Reason string `json:"reason"`
+}
+
+func (e *ParseError) Error() string {
+	switch {
+	case e.Sheet != "" && e.Axis != "":
+		return fmt.Sprintf("%s!%s: %s", e.Sheet, e.Axis, e.Reason)
+	case e.Sheet != "":
+		return fmt.Sprintf("%s: %s", e.Sheet, e.Reason)
+	}
+	return e.Reason
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Kind
+}
+
+// ParseErrors collects every ParseError in err's tree, e.g. the ones joined
+// by a strict parse.
+func ParseErrors(err error) []*ParseError {
+	if err == nil {
+		return nil
+	}
+	if pe, ok := err.(*ParseError); ok {
+		return []*ParseError{pe}
+	}
+
+	var out []*ParseError
+	switch u := err.(type) {
+	case interface{ Unwrap() []error }:
+		for _, e := range u.Unwrap() {
+			out = append(out, ParseErrors(e)...)
+		}
+	case interface{ Unwrap() error }:
+		out = ParseErrors(u.Unwrap())
+	}
+	return out
+}
+
+// problem records a diagnostic of a known kind and returns it as an error.
+func (r *ParseReport) problem(kind error, sev Severity, sheet, axis, value, format string, args ...interface{}) *ParseError {
+	r.add(sev, sheet, axis, value, format, args...)
+	d := &r.Diagnostics[len(r.Diagnostics)-1]
+	d.Code = errorCodes[kind]
+	return &ParseError{Kind: kind, Code: d.Code, Sheet: sheet, Axis: axis, Value: value, Reason: d.Reason}
+}
+
+// Problems returns the warnings and errors of a known kind as ParseErrors.
+func (r *ParseReport) Problems() []*ParseError {
+	kinds := make(map[string]error, len(errorCodes))
+	for kind, code := range errorCodes {
+		kinds[code] = kind
+	}
+
+	var out []*ParseError
+	for _, d := range r.Diagnostics {
+		if d.Code == "" || d.Severity == SeverityInfo {
+			continue
+		}
+		out = append(out, &ParseError{
+			Kind:   kinds[d.Code],
+			Code:   d.Code,
+			Sheet:  d.Sheet,
+			Axis:   d.Axis,
+			Value:  d.Value,
+			Reason: d.Reason,
+		})
+	}
+	return out
+}
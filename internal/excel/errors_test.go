@@ -0,0 +1,117 @@
+package excel
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStrictParseErrors(t *testing.T) {
+	good := testSheet{
+		name: "10 Пятница",
+		cells: map[string]any{
+			"C1": "10A",
+			"B2": "08:00-08:45", "C2": "Физика\nИванов И.И.\n301",
+		},
+	}
+
+	tests := []struct {
+		name  string
+		sheet testSheet
+		kind  error
+		code  string
+		axis  string
+	}{
+		{
+			name: "unknown day",
+			sheet: testSheet{
+				name: "9",
+				cells: map[string]any{
+					"C1": "9A",
+					"B2": "08:00-08:45", "C2": "Физика\nИванов И.И.\n301",
+				},
+			},
+			kind: ErrUnknownDay,
+			code: "unknown_day",
+		},
+		{
+			name: "bad time range",
+			sheet: testSheet{
+				name: "11 Пятница",
+				cells: map[string]any{
+					"C1": "11A",
+					"B2": "08:00-25:99", "C2": "Физика\nИванов И.И.\n301",
+				},
+			},
+			kind: ErrBadTimeRange,
+			code: "bad_time_range",
+			axis: "C2",
+		},
+		{
+			name: "unknown grade header",
+			sheet: testSheet{
+				name: "11 Пятница",
+				cells: map[string]any{
+					"C1": "11A", "D1": "Примечания",
+					"B2": "08:00-08:45", "C2": "Физика\nИванов И.И.\n301",
+				},
+			},
+			kind: ErrUnknownGradeHeader,
+			code: "unknown_grade_header",
+			axis: "D1",
+		},
+	}
+
+	layout := testLayout(t, func(l *Layout) { l.DaySource = DayFromSheetName })
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewParser(layout)
+			p.Strict = true
+			lessons, report, err := parseTest(t, p, good, tt.sheet)
+			if !errors.Is(err, tt.kind) {
+				t.Fatalf("err = %v, want %v", err, tt.kind)
+			}
+			if lessons != nil {
+				t.Errorf("strict parse returned %d lessons", len(lessons))
+			}
+			if report == nil {
+				t.Fatal("no report")
+			}
+
+			// An unknown day is reported for the sheet name and again when
+			// the sheet is rejected; every error must still point at it.
+			pes := ParseErrors(err)
+			if len(pes) == 0 {
+				t.Fatalf("ParseErrors(%v) is empty", err)
+			}
+			for _, pe := range pes {
+				if !errors.Is(pe, tt.kind) || pe.Code != tt.code || pe.Sheet != tt.sheet.name || pe.Axis != tt.axis {
+					t.Errorf("ParseError = %+v, want code %s at %s!%s", pe, tt.code, tt.sheet.name, tt.axis)
+				}
+			}
+
+			// Without Strict the same problem only skips what it touches.
+			p.Strict = false
+			if _, _, err := parseTest(t, p, good, tt.sheet); err != nil {
+				t.Errorf("lenient parse failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestEmptyWorkbook(t *testing.T) {
+	_, report, err := parseTest(t, nil, testSheet{
+		name:  "12 пн",
+		cells: map[string]any{"C1": "12A", "B2": "08:00-08:45"},
+	})
+	if !errors.Is(err, ErrEmptyWorkbook) {
+		t.Fatalf("err = %v, want %v", err, ErrEmptyWorkbook)
+	}
+	if report == nil || report.Count(SeverityError) != 1 {
+		t.Errorf("report = %+v, want one error", report)
+	}
+
+	var pe *ParseError
+	if !errors.As(err, &pe) || pe.Code != "empty_workbook" {
+		t.Errorf("err = %#v, want a ParseError with code empty_workbook", err)
+	}
+}
@@ -0,0 +1,459 @@
+package excel
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Where the weekday of a row comes from.
+const (
+	DayAuto          = "auto"       // column labels, then DayCell, then the sheet name
+	DayFromCell      = "cell"       // DayCell only
+	DayFromSheetName = "sheet_name" // the sheet name only
+	DayFromColumn    = "column"     // labels in DayColumn; may change down the sheet
+)
+
+// Roles a line inside a lesson cell can play.
+const (
+	LineName    = "name"
+	LineTeacher = "teacher"
+	LineRoom    = "room"
+	LineSkip    = "skip"
+)
+
+// Layout describes where things live in the timetable workbook. It is loaded
+// from YAML or JSON so template changes don't need a code change.
+type Layout struct {
+	// SheetPatterns are regular expressions; a sheet is parsed if its name
+	// matches any of them. The first capture group, or else the leading
+	// number of the sheet name, is taken as the sheet's parallel.
+	SheetPatterns []string `json:"sheet_patterns" yaml:"sheet_patterns"`
+	// Parallels limits the import to these grades. Empty means all.
+	Parallels []int `json:"parallels" yaml:"parallels"`
+	// AutoDetect looks for the header row and time column in the top rows
+	// of each sheet; HeaderRow and TimeColumn are used where nothing is found.
+	AutoDetect bool `json:"auto_detect" yaml:"auto_detect"`
+	// HeaderRow is the 1-based row holding grade headers such as "12A".
+	HeaderRow int `json:"header_row" yaml:"header_row"`
+	// TimeColumn holds "HH:MM-HH:MM" ranges. Columns up to and including it
+	// are never read as lessons.
+	TimeColumn string `json:"time_column" yaml:"time_column"`
+	// PeriodColumn optionally holds period numbers. Without it, periods are
+	// numbered by counting rows that have a time.
+	PeriodColumn string `json:"period_column" yaml:"period_column"`
+	// BellSchedule maps period numbers to "HH:MM-HH:MM" ranges. It is used
+	// when a row's time cell is empty or only has the start time.
+	BellSchedule map[int]string `json:"bell_schedule" yaml:"bell_schedule"`
+	// DaySource picks the rule for finding the weekday: auto, cell,
+	// sheet_name or column.
+	DaySource string `json:"day_source" yaml:"day_source"`
+	// DayCell holds the weekday of the whole sheet.
+	DayCell string `json:"day_cell" yaml:"day_cell"`
+	// DayColumn holds day labels for sheets with several days stacked
+	// vertically. A label applies to its row and every row below it until
+	// the next label.
+	DayColumn string `json:"day_column" yaml:"day_column"`
+	// CellLines assigns a role to each line of a lesson cell, in order.
+	CellLines []string `json:"cell_lines" yaml:"cell_lines"`
+	// StyleRules read lesson attributes from cell fill and font, e.g. a red
+	// fill for a cancelled lesson.
+	StyleRules []StyleRule `json:"style_rules" yaml:"style_rules"`
+	// Locales adds day names and header words to the built-in Russian,
+	// Kazakh and English dictionaries.
+	Locales []Locale `json:"locales" yaml:"locales"`
+
+	sheetPatterns []*regexp.Regexp
+	timeColumn    int
+	periodColumn  int
+	dayColumn     int
+	dayCellCol    int
+	dayCellRow    int
+	bells         map[int]Period
+	locales       []Locale
+}
+
+// DefaultLayout matches the template the school has been using: one sheet
+// per parallel named after its grade, day in A1, times in column B, headers
+// in row 1, and cells with name, teacher and room lines.
+func DefaultLayout() *Layout {
+	l := &Layout{
+		SheetPatterns: []string{`^\s*(\d{1,2})`},
+		AutoDetect:    true,
+		HeaderRow:     1,
+		TimeColumn:    "B",
+		DaySource:     DayAuto,
+		DayCell:       "A1",
+		DayColumn:     "A",
+		CellLines:     []string{LineName, LineTeacher, LineRoom},
+	}
+	if err := l.compile(); err != nil {
+		panic(err)
+	}
+	return l
+}
+
+// LoadLayout reads a layout descriptor. The format is picked by extension
+// (.yaml, .yml or .json); an empty path gives DefaultLayout. Fields left out
+// of the file keep their default values. Unknown keys are an error, so a
+// misspelled field is not silently replaced by its default.
+func LoadLayout(path string) (*Layout, error) {
+	if path == "" {
+		return DefaultLayout(), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read layout: %w", err)
+	}
+
+	l := DefaultLayout()
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err = dec.Decode(l); errors.Is(err, io.EOF) {
+			err = nil // an empty file keeps every default
+		}
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(l)
+	default:
+		return nil, fmt.Errorf("unsupported layout format %q", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode layout %s: %w", path, err)
+	}
+
+	if err := l.compile(); err != nil {
+		return nil, fmt.Errorf("invalid layout %s: %w", path, err)
+	}
+	return l, nil
+}
+
+// compile validates the layout and prepares its derived fields.
+func (l *Layout) compile() error {
+	l.sheetPatterns = l.sheetPatterns[:0]
+	for _, p := range l.SheetPatterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return fmt.Errorf("sheet pattern %q: %w", p, err)
+		}
+		l.sheetPatterns = append(l.sheetPatterns, re)
+	}
+
+	if l.HeaderRow < 1 {
+		return fmt.Errorf("header_row must be 1 or greater, got %d", l.HeaderRow)
+	}
+
+	col, err := excelize.ColumnNameToNumber(l.TimeColumn)
+	if err != nil {
+		return fmt.Errorf("time_column: %w", err)
+	}
+	l.timeColumn = col
+
+	l.periodColumn = 0
+	if l.PeriodColumn != "" {
+		if l.periodColumn, err = excelize.ColumnNameToNumber(l.PeriodColumn); err != nil {
+			return fmt.Errorf("period_column: %w", err)
+		}
+	}
+
+	l.bells = make(map[int]Period, len(l.BellSchedule))
+	for period, value := range l.BellSchedule {
+		start, end, hasEnd, err := parseTimeRange(value)
+		if err == nil && !hasEnd {
+			err = fmt.Errorf("missing end time")
+		}
+		if err != nil {
+			return fmt.Errorf("bell_schedule period %d: %w", period, err)
+		}
+		l.bells[period] = Period{Start: start, End: end}
+	}
+
+	switch l.DaySource {
+	case DayAuto, DayFromCell, DayFromSheetName, DayFromColumn:
+	default:
+		return fmt.Errorf("unknown day_source %q", l.DaySource)
+	}
+	if l.dayCellCol, l.dayCellRow, err = excelize.CellNameToCoordinates(l.DayCell); err != nil {
+		return fmt.Errorf("day_cell: %w", err)
+	}
+	if l.dayColumn, err = excelize.ColumnNameToNumber(l.DayColumn); err != nil {
+		return fmt.Errorf("day_column: %w", err)
+	}
+
+	for _, loc := range l.Locales {
+		if loc.Name == "" {
+			return fmt.Errorf("locale without a name")
+		}
+		for day := range loc.Days {
+			if day < 1 || day > 7 {
+				return fmt.Errorf("locale %s: day %d out of range 1-7", loc.Name, day)
+			}
+		}
+		for day := range loc.DayAbbreviations {
+			if day < 1 || day > 7 {
+				return fmt.Errorf("locale %s: day %d out of range 1-7", loc.Name, day)
+			}
+		}
+	}
+	l.locales = mergeLocales(l.Locales)
+
+	for i := range l.StyleRules {
+		if err := l.StyleRules[i].compile(); err != nil {
+			return fmt.Errorf("style_rules[%d]: %w", i, err)
+		}
+	}
+
+	hasName := false
+	for _, role := range l.CellLines {
+		switch role {
+		case LineName:
+			hasName = true
+		case LineTeacher, LineRoom, LineSkip:
+		default:
+			return fmt.Errorf("unknown cell line role %q", role)
+		}
+	}
+	if !hasName {
+		return fmt.Errorf("cell_lines must contain %q", LineName)
+	}
+
+	return nil
+}
+
+// matchesSheet reports whether a sheet should be parsed.
+func (l *Layout) matchesSheet(name string) bool {
+	for _, re := range l.sheetPatterns {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+var leadingNumber = regexp.MustCompile(`^\s*(\d{1,2})`)
+
+// sheetParallel guesses the grade a sheet belongs to, or 0 if unknown.
+func (l *Layout) sheetParallel(name string) int {
+	for _, re := range l.sheetPatterns {
+		m := re.FindStringSubmatch(name)
+		if len(m) > 1 {
+			if n, err := strconv.Atoi(m[1]); err == nil {
+				return n
+			}
+		}
+	}
+	if m := leadingNumber.FindStringSubmatch(name); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		return n
+	}
+	return 0
+}
+
+// hasParallel reports whether the layout imports the given grade.
+func (l *Layout) hasParallel(grade int) bool {
+	if len(l.Parallels) == 0 {
+		return true
+	}
+	for _, p := range l.Parallels {
+		if p == grade {
+			return true
+		}
+	}
+	return false
+}
+
+// isLessonColumn reports whether a 1-based column can hold lessons.
+func (l *Layout) isLessonColumn(col int) bool {
+	return col > l.timeColumn && col != l.periodColumn
+}
+
+// cellBlocks splits a lesson cell holding several subgroups into one block
+// per subgroup. Three forms are recognised:
+//
+//	"Англ №1 / Англ №2\nSmith / Brown\n204 / 205"  slash-separated columns
+//	"Англ №1\nSmith\n204\n\nАнгл №2\nBrown\n205" blocks separated by blank lines
+//	"Англ №1\nSmith\n204\nАнгл №2\nBrown\n205"   one block per len(CellLines) lines
+//
+// Lines are only split by count when each block starts with a numbered line
+// or at least one that is neither a teacher nor a room.
+// A slash in the first line only splits the cell when every part carries a
+// group marker ("№") or every other line has as many slash parts, so names
+// such as "Каз.яз/лит" or "Физика/Химия" stay one lesson. Anything else is a
+// single block.
+func (l *Layout) cellBlocks(value string) []string {
+	value = strings.TrimSpace(strings.ReplaceAll(value, "\r\n", "\n"))
+	lines := strings.Split(value, "\n")
+
+	if parts := splitSlash(lines[0]); len(parts) > 1 && slashColumns(parts, lines[1:]) {
+		blocks := make([][]string, len(parts))
+		for _, line := range lines {
+			cols := splitSlash(line)
+			for i := range blocks {
+				switch {
+				case len(cols) == len(parts):
+					blocks[i] = append(blocks[i], cols[i])
+				case len(cols) == 1:
+					blocks[i] = append(blocks[i], cols[0]) // shared, e.g. one teacher
+				default:
+					blocks[i] = append(blocks[i], "")
+				}
+			}
+		}
+		out := make([]string, len(blocks))
+		for i, b := range blocks {
+			out[i] = strings.Join(b, "\n")
+		}
+		return out
+	}
+
+	var blocks []string
+	var current []string
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			if len(current) > 0 {
+				blocks = append(blocks, strings.Join(current, "\n"))
+				current = nil
+			}
+			continue
+		}
+		current = append(current, line)
+	}
+	if len(current) > 0 {
+		blocks = append(blocks, strings.Join(current, "\n"))
+	}
+	if len(blocks) > 1 {
+		return blocks
+	}
+
+	size := len(l.CellLines)
+	if size > 1 && len(lines) >= 2*size && len(lines)%size == 0 && blockStarts(lines, size) {
+		blocks = blocks[:0]
+		for i := 0; i < len(lines); i += size {
+			blocks = append(blocks, strings.Join(lines[i:i+size], "\n"))
+		}
+		return blocks
+	}
+	return []string{value}
+}
+
+// blockStarts reports whether every size-th line can start a subgroup block:
+// it is numbered ("№") or at least isn't a teacher or room line. A single
+// long lesson whose detail lines happen to fall there stays whole.
+func blockStarts(lines []string, size int) bool {
+	for i := 0; i < len(lines); i += size {
+		if !strings.Contains(lines[i], "№") && classifyLine(lines[i]) != kindUnknown {
+			return false
+		}
+	}
+	return true
+}
+
+// slashColumns reports whether a first line split into parts really lists
+// subgroups: each part is numbered, or the detail lines split the same way.
+func slashColumns(parts, rest []string) bool {
+	numbered := true
+	for _, p := range parts {
+		numbered = numbered && strings.Contains(p, "№")
+	}
+	if numbered {
+		return true
+	}
+
+	details := 0
+	for _, line := range rest {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if len(splitSlash(line)) != len(parts) {
+			return false
+		}
+		details++
+	}
+	return details > 0
+}
+
+// splitSlash splits a line on "/" separators, trimming each part. A line
+// without one is returned as a single part.
+func splitSlash(line string) []string {
+	parts := strings.Split(line, "/")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return parts
+}
+
+// cellDetails splits a lesson cell into its name, teacher and room. The name
+// is taken from the line CellLines marks as the name. Every other line that
+// isn't skipped is classified on its own, so a swapped or missing teacher or
+// room still lands in the right field. Lines that can't be classified fall
+// back to their CellLines role; uncertain lists those guesses.
+func (l *Layout) cellDetails(value string) (name, teacher, room string, uncertain []string) {
+	lines := strings.Split(value, "\n")
+
+	type detail struct {
+		line string
+		role string // from CellLines, "" past its end
+		kind lineKind
+	}
+	var details []detail
+	for i, raw := range lines {
+		line := strings.TrimSpace(raw)
+		role := ""
+		if i < len(l.CellLines) {
+			role = l.CellLines[i]
+		}
+		switch {
+		case line == "" || role == LineSkip:
+			continue
+		case role == LineName:
+			name = line
+			continue
+		}
+		details = append(details, detail{line: line, role: role, kind: classifyLine(line)})
+	}
+
+	for _, d := range details {
+		switch {
+		case d.kind == kindTeacher && teacher == "":
+			teacher = d.line
+		case d.kind == kindRoom && room == "":
+			room = d.line
+		case d.kind != kindUnknown:
+			uncertain = append(uncertain, fmt.Sprintf("second %s line %q ignored", d.kind, d.line))
+		}
+	}
+
+	for _, d := range details {
+		if d.kind != kindUnknown {
+			continue
+		}
+		switch {
+		case d.role == LineTeacher && teacher == "":
+			teacher = d.line
+			uncertain = append(uncertain, fmt.Sprintf("%q assumed to be the teacher", d.line))
+		case room == "":
+			room = d.line
+			uncertain = append(uncertain, fmt.Sprintf("%q assumed to be the room", d.line))
+		case teacher == "":
+			teacher = d.line
+			uncertain = append(uncertain, fmt.Sprintf("%q assumed to be the teacher", d.line))
+		default:
+			uncertain = append(uncertain, fmt.Sprintf("unrecognised line %q ignored", d.line))
+		}
+	}
+	return name, teacher, room, uncertain
+}
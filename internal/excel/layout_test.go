@@ -0,0 +1,64 @@
+package excel
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCellBlocks(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  []string
+	}{
+		{
+			name:  "single lesson",
+			value: "Физика\nИванов И.И.\n301",
+			want:  []string{"Физика\nИванов И.И.\n301"},
+		},
+		{
+			name:  "slash subgroups with numbers",
+			value: "Англ №1 / Англ №2\nSmith J.\n204 / 205",
+			want:  []string{"Англ №1\nSmith J.\n204", "Англ №2\nSmith J.\n205"},
+		},
+		{
+			name:  "slash columns in every line",
+			value: "Англ / Нем\nSmith J. / Brown K.\n204 / 205",
+			want:  []string{"Англ\nSmith J.\n204", "Нем\nBrown K.\n205"},
+		},
+		{
+			name:  "slash in a subject name",
+			value: "Каз.яз/лит\nАбаев А.\n210",
+			want:  []string{"Каз.яз/лит\nАбаев А.\n210"},
+		},
+		{
+			name:  "slash in a lone name",
+			value: "Физика/Химия",
+			want:  []string{"Физика/Химия"},
+		},
+		{
+			name:  "blank line between subgroups",
+			value: "Англ №1\nSmith J.\n204\n\nАнгл №2\nBrown K.\n205",
+			want:  []string{"Англ №1\nSmith J.\n204", "Англ №2\nBrown K.\n205"},
+		},
+		{
+			name:  "one block per CellLines lines",
+			value: "Англ №1\nSmith J.\n204\nАнгл №2\nBrown K.\n205",
+			want:  []string{"Англ №1\nSmith J.\n204", "Англ №2\nBrown K.\n205"},
+		},
+		{
+			name:  "six-line lesson is not split by count",
+			value: "Физика\nИванов И.И.\n301\nПетров П.П.\nпо подгруппам\n302",
+			want:  []string{"Физика\nИванов И.И.\n301\nПетров П.П.\nпо подгруппам\n302"},
+		},
+	}
+
+	l := DefaultLayout()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := l.cellBlocks(tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("cellBlocks(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package excel
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Locale is the vocabulary of one timetable language.
+type Locale struct {
+	Name string `json:"name" yaml:"name"`
+	// Days maps 1=Mon … 7=Sun to full names or stems. A word matches when it
+	// starts with one of them, so stems cover inflected forms.
+	Days map[int][]string `json:"days" yaml:"days"`
+	// DayAbbreviations are short forms that only match a whole word ("пн").
+	DayAbbreviations map[int][]string `json:"day_abbreviations" yaml:"day_abbreviations"`
+	// HeaderWords are dropped from grade headers ("12 А класс" → "12А").
+	HeaderWords []string `json:"header_words" yaml:"header_words"`
+}
+
+// builtinLocales are always available; Layout.Locales can extend them.
+var builtinLocales = []Locale{
+	{
+		Name: "ru",
+		Days: map[int][]string{
+			1: {"понедельник"},
+			2: {"вторник"},
+			3: {"сред"},
+			4: {"четверг"},
+			5: {"пятниц"},
+			6: {"суббот"},
+			7: {"воскресен"},
+		},
+		DayAbbreviations: map[int][]string{
+			1: {"пн", "пнд"},
+			2: {"вт", "втр"},
+			3: {"ср", "срд"},
+			4: {"чт", "чтв"},
+			5: {"пт", "птн"},
+			6: {"сб", "суб"},
+			7: {"вс", "вск"},
+		},
+		HeaderWords: []string{"класс", "классы", "кл"},
+	},
+	{
+		Name: "kk",
+		Days: map[int][]string{
+			1: {"дүйсенбі", "дуйсенби"},
+			2: {"сейсенбі", "сейсенби"},
+			3: {"сәрсенбі", "сарсенби"},
+			4: {"бейсенбі", "бейсенби"},
+			5: {"жұма", "жума"},
+			6: {"сенбі", "сенби"},
+			7: {"жексенбі", "жексенби"},
+		},
+		DayAbbreviations: map[int][]string{
+			1: {"дс"},
+			2: {"сс"},
+			3: {"ср"},
+			4: {"бс"},
+			5: {"жм"},
+			6: {"сн"},
+			7: {"жс"},
+		},
+		HeaderWords: []string{"сынып", "сыныптар", "сын"},
+	},
+	{
+		Name: "en",
+		Days: map[int][]string{
+			1: {"monday"},
+			2: {"tuesday"},
+			3: {"wednesday"},
+			4: {"thursday"},
+			5: {"friday"},
+			6: {"saturday"},
+			7: {"sunday"},
+		},
+		DayAbbreviations: map[int][]string{
+			1: {"mon"},
+			2: {"tue", "tues"},
+			3: {"wed"},
+			4: {"thu", "thur", "thurs"},
+			5: {"fri"},
+			6: {"sat"},
+			7: {"sun"},
+		},
+		HeaderWords: []string{"grade", "class", "form"},
+	},
+}
+
+// mergeLocales returns the built-in locales extended by extra. An extra
+// locale with a built-in name adds its words to that locale.
+func mergeLocales(extra []Locale) []Locale {
+	out := make([]Locale, 0, len(builtinLocales)+len(extra))
+	for _, l := range builtinLocales {
+		out = append(out, copyLocale(l))
+	}
+
+	for _, e := range extra {
+		i := -1
+		for j := range out {
+			if out[j].Name == e.Name {
+				i = j
+				break
+			}
+		}
+		if i == -1 {
+			out = append(out, copyLocale(Locale{Name: e.Name}))
+			i = len(out) - 1
+		}
+
+		for day, words := range e.Days {
+			out[i].Days[day] = append(out[i].Days[day], lowerAll(words)...)
+		}
+		for day, words := range e.DayAbbreviations {
+			out[i].DayAbbreviations[day] = append(out[i].DayAbbreviations[day], lowerAll(words)...)
+		}
+		out[i].HeaderWords = append(out[i].HeaderWords, lowerAll(e.HeaderWords)...)
+	}
+	return out
+}
+
+func copyLocale(l Locale) Locale {
+	c := Locale{
+		Name:             l.Name,
+		Days:             make(map[int][]string),
+		DayAbbreviations: make(map[int][]string),
+		HeaderWords:      append([]string(nil), l.HeaderWords...),
+	}
+	for d, w := range l.Days {
+		c.Days[d] = append([]string(nil), w...)
+	}
+	for d, w := range l.DayAbbreviations {
+		c.DayAbbreviations[d] = append([]string(nil), w...)
+	}
+	return c
+}
+
+func lowerAll(words []string) []string {
+	out := make([]string, len(words))
+	for i, w := range words {
+		out[i] = strings.ToLower(strings.TrimSpace(w))
+	}
+	return out
+}
+
+// words splits s into lowercase runs of letters.
+func words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+}
+
+// parseDay finds the weekday named in s, e.g. "Понедельник", "Дүйсенбі",
+// "Пн" or "12 Mon". It returns 0 if no locale knows any of the words.
+func (l *Layout) parseDay(s string) (day int, locale string) {
+	for _, w := range words(s) {
+		for _, loc := range l.locales {
+			for d := 1; d <= 7; d++ {
+				for _, name := range loc.Days[d] {
+					if strings.HasPrefix(w, name) {
+						return d, loc.Name
+					}
+				}
+				for _, abbr := range loc.DayAbbreviations[d] {
+					if w == abbr {
+						return d, loc.Name
+					}
+				}
+			}
+		}
+	}
+	return 0, ""
+}
+
+var gradeHeaderPattern = regexp.MustCompile(`^(\d{1,2})\s*[-–—]?\s*["'«“]?\s*(\p{L})\s*["'»”]?$`)
+
+// parseGradeHeader reads a class header such as "12A", "11 Б", "9-В класс"
+// or "10 «Ә» сынып". The returned locale is the one whose header word was
+// found, if any.
+func (l *Layout) parseGradeHeader(s string) (grade int, letter, locale string, ok bool) {
+	s = strings.TrimSpace(s)
+	for _, w := range words(s) {
+		for _, loc := range l.locales {
+			if containsString(loc.HeaderWords, w) {
+				if locale == "" {
+					locale = loc.Name
+				}
+				s = removeFold(s, w)
+			}
+		}
+	}
+
+	m := gradeHeaderPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0, "", "", false
+	}
+	grade, err := strconv.Atoi(m[1])
+	if err != nil || grade < 1 || grade > 12 {
+		return 0, "", "", false
+	}
+	return grade, strings.ToUpper(m[2]), locale, true
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// removeFold deletes the first case-insensitive occurrence of word from s.
+func removeFold(s, word string) string {
+	runes := []rune(s)
+	target := []rune(word)
+	for i := 0; i+len(target) <= len(runes); i++ {
+		if strings.EqualFold(string(runes[i:i+len(target)]), word) {
+			return string(runes[:i]) + string(runes[i+len(target):])
+		}
+	}
+	return s
+}
@@ -0,0 +1,91 @@
+package excel
+
+import "testing"
+
+func TestParseDay(t *testing.T) {
+	tests := []struct {
+		in     string
+		day    int
+		locale string
+	}{
+		{"Понедельник", 1, "ru"},
+		{"ВТОРНИК", 2, "ru"},
+		{"в среду", 3, "ru"},
+		{"Пт", 5, "ru"},
+		{"Дүйсенбі", 1, "kk"},
+		{"сейсенби", 2, "kk"},
+		{"Жұма", 5, "kk"},
+		{"Сенбі", 6, "kk"},
+		{"Wednesday", 3, "en"},
+		{"12 Mon", 1, "en"},
+		{"Thurs.", 4, "en"},
+		{"Обед", 0, ""},
+		{"", 0, ""},
+	}
+
+	l := DefaultLayout()
+	for _, tt := range tests {
+		day, locale := l.parseDay(tt.in)
+		if day != tt.day || locale != tt.locale {
+			t.Errorf("parseDay(%q) = %d, %q; want %d, %q", tt.in, day, locale, tt.day, tt.locale)
+		}
+	}
+}
+
+func TestParseDayExtraLocale(t *testing.T) {
+	l := testLayout(t, func(l *Layout) {
+		l.Locales = []Locale{{Name: "de", Days: map[int][]string{1: {"Montag"}}}}
+	})
+	if day, locale := l.parseDay("Montag"); day != 1 || locale != "de" {
+		t.Errorf("parseDay(Montag) = %d, %q; want 1, de", day, locale)
+	}
+}
+
+func TestParseGradeHeader(t *testing.T) {
+	tests := []struct {
+		in     string
+		grade  int
+		letter string
+		locale string
+		ok     bool
+	}{
+		{"12A", 12, "A", "", true},
+		{"11 Б", 11, "Б", "", true},
+		{"9-В класс", 9, "В", "ru", true},
+		{"8 б класс", 8, "Б", "ru", true},
+		{"10 «Ә» сынып", 10, "Ә", "kk", true},
+		{"7 ғ сынып", 7, "Ғ", "kk", true},
+		{"Grade 11 C", 11, "C", "en", true},
+		{"11 C grade", 11, "C", "en", true},
+		{"13A", 0, "", "", false},
+		{"Время", 0, "", "", false},
+		{"12", 0, "", "", false},
+		{"", 0, "", "", false},
+	}
+
+	l := DefaultLayout()
+	for _, tt := range tests {
+		grade, letter, locale, ok := l.parseGradeHeader(tt.in)
+		if ok != tt.ok || grade != tt.grade || letter != tt.letter || (ok && locale != tt.locale) {
+			t.Errorf("parseGradeHeader(%q) = %d, %q, %q, %v; want %d, %q, %q, %v",
+				tt.in, grade, letter, locale, ok, tt.grade, tt.letter, tt.locale, tt.ok)
+		}
+	}
+}
+
+func TestKazakhSheet(t *testing.T) {
+	lessons, report := mustParse(t, nil, testSheet{
+		name: "10 сынып",
+		cells: map[string]any{
+			"A1": "Сәрсенбі", "C1": "10 «Ә» сынып",
+			"B2": "08:00-08:45", "C2": "Қазақ тілі\nАбаев А.\n210",
+		},
+	})
+
+	if l := findLesson(t, lessons, "10Ә", "Қазақ тілі"); l.LessonDay != 3 {
+		t.Errorf("lesson on day %d, want 3", l.LessonDay)
+	}
+	if got := report.Locales["10 сынып"]; len(got) != 1 || got[0] != "kk" {
+		t.Errorf("sheet locales = %v, want [kk]", got)
+	}
+}
@@ -0,0 +1,72 @@
+package excel
+
+import "testing"
+
+func TestMergedAcrossClasses(t *testing.T) {
+	lessons, _ := mustParse(t, nil, testSheet{
+		name: "12 пн",
+		cells: map[string]any{
+			"C1": "12A", "D1": "12B", "E1": "12C",
+			"B2": "08:00-08:45", "C2": "История\nИванов И.И.\n301",
+			"B3": "08:50-09:35", "C3": "Физика\nПетров П.П.\n302",
+		},
+		merges: [][2]string{{"C2", "E2"}},
+	})
+
+	var stream string
+	for _, class := range []string{"12A", "12B", "12C"} {
+		l := findLesson(t, lessons, class, "История")
+		if l.StreamID == "" {
+			t.Fatalf("%s: История has no stream", class)
+		}
+		if stream == "" {
+			stream = l.StreamID
+		}
+		if l.StreamID != stream {
+			t.Errorf("%s: stream %q, want %q", class, l.StreamID, stream)
+		}
+		if l.LessonTeacher != "Иванов И.И." || l.LessonClass != "301" {
+			t.Errorf("%s: teacher %q, room %q", class, l.LessonTeacher, l.LessonClass)
+		}
+	}
+	if stream != "12 пн!C2:E2" {
+		t.Errorf("stream = %q, want %q", stream, "12 пн!C2:E2")
+	}
+
+	if l := findLesson(t, lessons, "12A", "Физика"); l.StreamID != "" {
+		t.Errorf("unmerged lesson has stream %q", l.StreamID)
+	}
+}
+
+func TestMergedDoublePeriod(t *testing.T) {
+	lessons, _ := mustParse(t, nil, testSheet{
+		name: "12 пн",
+		cells: map[string]any{
+			"C1": "12A",
+			"B2": "08:00-08:45", "C2": "Алгебра\nКим К.К.\n210",
+			"B3": "08:50-09:35", "C3": "Физика\nПетров П.П.\n302",
+			"B4": "09:45-10:30", "C4": "Химия\nСеров С.С.\n305",
+			"B5": "10:40-11:25",
+			"B6": "11:35-12:20", "C6": "Биология\nПетрова А.А.\n306",
+		},
+		merges: [][2]string{{"C4", "C5"}},
+	})
+
+	l := findLesson(t, lessons, "12A", "Химия")
+	if l.Period != 3 || l.PeriodEnd != 4 {
+		t.Errorf("periods %d-%d, want 3-4", l.Period, l.PeriodEnd)
+	}
+	if start, end := l.LessonStart.Format("15:04"), l.LessonEnd.Format("15:04"); start != "09:45" || end != "11:25" {
+		t.Errorf("time %s-%s, want 09:45-11:25", start, end)
+	}
+	if l.StreamID != "" {
+		t.Errorf("double period has stream %q", l.StreamID)
+	}
+
+	if l := findLesson(t, lessons, "12A", "Биология"); l.Period != 5 || l.PeriodEnd != 5 {
+		t.Errorf("Биология periods %d-%d, want 5-5", l.Period, l.PeriodEnd)
+	}
+	if len(lessons) != 4 {
+		t.Errorf("got %d lessons, want 4", len(lessons))
+	}
+}
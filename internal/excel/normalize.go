@@ -0,0 +1,113 @@
+package excel
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/in-nis/cnis-back/internal/models"
+)
+
+// LessonKey is the natural identity of a lesson: grade, letter, day, period,
+// subject and group. It does not depend on row order or DB IDs, so the same
+// lesson keeps the same key across imports.
+func LessonKey(l models.Lesson) string {
+	canonical := fmt.Sprintf("%d|%s|%d|%d|%s|%s",
+		l.Grade,
+		canonicalText(l.GradeLetter),
+		l.LessonDay,
+		l.Period,
+		canonicalText(l.LessonName),
+		canonicalText(l.LessonGroup),
+	)
+	sum := sha1.Sum([]byte(canonical))
+	return hex.EncodeToString(sum[:])
+}
+
+// maxKeyLen is the size of the TeacherKey and RoomKey columns.
+const maxKeyLen = 128
+
+// boundKey keeps a natural key within maxKeyLen bytes. A longer key, e.g.
+// from a whole misread cell, is replaced by its hash so it still matches
+// itself on the next import.
+func boundKey(key string) string {
+	if len(key) <= maxKeyLen {
+		return key
+	}
+	sum := sha1.Sum([]byte(key))
+	return "#" + hex.EncodeToString(sum[:])
+}
+
+// canonicalText lowercases s and collapses all whitespace to single spaces.
+func canonicalText(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+// Normalize tidies teacher names, assigns every lesson its LessonKey,
+// TeacherKey and RoomKey and merges lessons that share a LessonKey. Merged
+// lessons keep the widest time span and the first non-empty teacher, room,
+// stream and style attributes. The order of first appearance is preserved.
+func Normalize(lessons []models.Lesson, report *ParseReport) []models.Lesson {
+	out := make([]models.Lesson, 0, len(lessons))
+	index := make(map[string]int, len(lessons))
+	merged := 0
+
+	for _, l := range lessons {
+		l.LessonKey = LessonKey(l)
+		l.LessonTeacher = TeacherName(l.LessonTeacher)
+		l.TeacherKey = TeacherKey(l.LessonTeacher)
+		l.RoomKey = RoomKey(l.LessonClass, l.Building)
+
+		i, ok := index[l.LessonKey]
+		if !ok {
+			index[l.LessonKey] = len(out)
+			out = append(out, l)
+			continue
+		}
+
+		merged++
+		existing := &out[i]
+		if l.LessonStart.Before(existing.LessonStart) {
+			existing.LessonStart = l.LessonStart
+		}
+		if l.LessonEnd.After(existing.LessonEnd) {
+			existing.LessonEnd = l.LessonEnd
+		}
+		if l.PeriodEnd > existing.PeriodEnd {
+			existing.PeriodEnd = l.PeriodEnd
+		}
+		if existing.TeacherKey != l.TeacherKey || len(existing.LessonTeacher) >= len(l.LessonTeacher) {
+			existing.LessonTeacher = mergeText(existing.LessonTeacher, l.LessonTeacher, "teacher", existing, report)
+		} else {
+			existing.LessonTeacher = l.LessonTeacher // same teacher, fuller spelling
+		}
+		existing.TeacherKey = TeacherKey(existing.LessonTeacher)
+		existing.LessonClass = mergeText(existing.LessonClass, l.LessonClass, "room", existing, report)
+		if existing.StreamID == "" {
+			existing.StreamID = l.StreamID
+		}
+		existing.Status = mergeText(existing.Status, l.Status, "status", existing, report)
+		existing.Format = mergeText(existing.Format, l.Format, "format", existing, report)
+		existing.Building = mergeText(existing.Building, l.Building, "building", existing, report)
+		existing.RoomKey = RoomKey(existing.LessonClass, existing.Building)
+	}
+
+	if merged > 0 {
+		report.info("", "", "", "merged %d duplicate lessons", merged)
+	}
+	return out
+}
+
+// mergeText keeps a if set, otherwise b. Two different non-empty values are
+// reported since one of them is lost.
+func mergeText(a, b, field string, l *models.Lesson, report *ParseReport) string {
+	if a == "" {
+		return b
+	}
+	if b != "" && canonicalText(a) != canonicalText(b) {
+		report.warn("", "", b, "duplicate %d%s %s (day %d, period %d) has conflicting %s; keeping %q",
+			l.Grade, l.GradeLetter, l.LessonName, l.LessonDay, l.Period, field, a)
+	}
+	return a
+}
@@ -2,315 +2,619 @@ package excel
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"log"
-	"net/http"
-	"os"
+	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/xuri/excelize/v2"
-	"github.com/in-nis/cnis-back/internal/db"
 	lesson "github.com/in-nis/cnis-back/internal/models"
 )
 
-var baseUrl = "https://docs.google.com/spreadsheets/d/1KbzUHfsSwywWOzswZzdtIcdWzeZUNsI1/export?format=xlsx&id=1KbzUHfsSwywWOzswZzdtIcdWzeZUNsI1"
-
-// -------------------- DOWNLOAD --------------------
-
-func GetExcel() (string, error) {
-	log.Println("📥 Downloading Excel from:", baseUrl)
+// -------------------- PARSING --------------------
 
-	resp, err := http.Get(baseUrl)
-	if err != nil {
-		return "", fmt.Errorf("failed to fetch excel: %w", err)
-	}
-	defer resp.Body.Close()
+// Parser turns a timetable workbook into lessons according to a Layout.
+type Parser struct {
+	Layout *Layout
+	// Disabled holds parallels an admin has switched off.
+	Disabled map[int]bool
+	// Workers caps how many sheets are parsed at once; 0 means GOMAXPROCS.
+	Workers int
+	// Strict fails the parse on any known problem (see ParseError), even
+	// one that would only skip a cell.
+	Strict bool
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("bad status: %s", resp.Status)
+// NewParser returns a parser for layout; nil means DefaultLayout.
+func NewParser(layout *Layout) *Parser {
+	if layout == nil {
+		layout = DefaultLayout()
 	}
+	return &Parser{Layout: layout}
+}
 
-	filePath := "sheet.xlsx"
-	out, err := os.Create(filePath)
-	if err != nil {
-		return "", fmt.Errorf("failed to create file: %w", err)
-	}
-	defer out.Close()
+// WithDisabled returns a copy of p that skips the given parallels.
+func (p *Parser) WithDisabled(disabled map[int]bool) *Parser {
+	cp := *p
+	cp.Disabled = disabled
+	return &cp
+}
 
-	if _, err = io.Copy(out, resp.Body); err != nil {
-		return "", fmt.Errorf("failed to save file: %w", err)
-	}
+// Fingerprint identifies the settings that shape the parse result: the
+// layout and the disabled parallels. Two parsers with the same fingerprint
+// turn a workbook into the same lessons.
+func (p *Parser) Fingerprint() string {
+	data, _ := json.Marshal(struct {
+		Layout   *Layout
+		Disabled map[int]bool
+	}{p.Layout, p.Disabled})
+	return contentHash(data)
+}
 
-	log.Println("✅ Excel saved to", filePath)
-	return filePath, nil
+// wantsParallel reports whether lessons of grade should be imported.
+func (p *Parser) wantsParallel(grade int) bool {
+	return p.Layout.hasParallel(grade) && !p.Disabled[grade]
 }
 
-// -------------------- PARSING --------------------
+// ParseExcel reads the workbook provided by src using the default layout.
+func ParseExcel(ctx context.Context, src ScheduleSource) ([]lesson.Lesson, *ParseReport, error) {
+	return NewParser(nil).Parse(ctx, src)
+}
 
-func ParseExcel(path string) ([]lesson.Lesson, error) {
-	log.Println("📖 Opening Excel file:", path)
+// Parse reads the workbook provided by src. It does not touch the DB;
+// see Service.Import for publishing the result. The report is returned even
+// when parsing fails, so callers can show what went wrong.
+//
+// A workbook without lessons fails with ErrEmptyWorkbook. In strict mode
+// every known problem is returned, joined; use ParseErrors to list them.
+func (p *Parser) Parse(ctx context.Context, src ScheduleSource) ([]lesson.Lesson, *ParseReport, error) {
+	log.Println("📖 Opening Excel from:", src)
+	report := newReport(src.String())
+	defer func() { report.FinishedAt = time.Now() }()
+
+	r, err := src.Open(ctx)
+	if err != nil {
+		report.fail("", "", "", "failed to open source: %v", err)
+		return nil, report, err
+	}
+	defer r.Close()
 
-	f, err := excelize.OpenFile(path)
+	f, err := excelize.OpenReader(r)
 	if err != nil {
-		return nil, err
+		report.fail("", "", "", "failed to read workbook: %v", err)
+		return nil, report, fmt.Errorf("failed to read workbook from %s: %w", src, err)
 	}
 	defer f.Close()
 
+	var jobs []sheetJob
+	for _, sheetName := range f.GetSheetList() {
+		if !p.Layout.matchesSheet(sheetName) {
+			report.info(sheetName, "", "", "sheet skipped: name matches no sheet pattern")
+			continue
+		}
+
+		parallel := p.Layout.sheetParallel(sheetName)
+		if parallel != 0 && !p.wantsParallel(parallel) {
+			report.parallel(parallel).Disabled = true
+			report.info(sheetName, "", "", "sheet skipped: parallel %d is disabled", parallel)
+			continue
+		}
+		jobs = append(jobs, sheetJob{sheet: sheetName, parallel: parallel})
+	}
+
 	var lessons []lesson.Lesson
+	for i, res := range p.parseSheets(ctx, f, src.String(), jobs) {
+		sheetName, parallel := jobs[i].sheet, jobs[i].parallel
+		report.Sheets = append(report.Sheets, sheetName)
+		report.merge(res.report)
+		if res.err != nil {
+			report.fail(sheetName, "", "", "failed to read sheet: %v", res.err)
+			return nil, report, fmt.Errorf("error parsing sheet %s: %w", sheetName, res.err)
+		}
 
-	for _, sheetName := range f.GetSheetList() {
-		if strings.HasPrefix(sheetName, "12") {
-			log.Println("➡️ Parsing sheet:", sheetName)
+		if parallel != 0 {
+			ps := report.parallel(parallel)
+			ps.Sheets = append(ps.Sheets, sheetName)
+			ps.Warnings += res.report.Count(SeverityWarning)
+			ps.Errors += res.report.Count(SeverityError)
+		}
 
-			sheetLessons, err := parseSheet(f, sheetName)
-			if err != nil {
-				return nil, fmt.Errorf("error parsing sheet %s: %w", sheetName, err)
-			}
+		log.Printf("✅ Parsed %d lessons from sheet %s\n", len(res.lessons), sheetName)
+
+		// Print each lesson (excluding time)
+		for _, l := range res.lessons {
+			log.Printf("   ➡️ Grade: %d%s | Day: %d | Name: %s | Group: %s | Teacher: %s | Class: %s",
+				l.Grade,
+				l.GradeLetter,
+				l.LessonDay,
+				l.LessonName,
+				l.LessonGroup,
+				l.LessonTeacher,
+				l.LessonClass,
+			)
+		}
+		lessons = append(lessons, res.lessons...)
+	}
+
+	lessons = Normalize(lessons, report)
+	for _, l := range lessons {
+		report.parallel(l.Grade).Lessons++
+	}
 
-			log.Printf("✅ Parsed %d lessons from sheet %s\n", len(sheetLessons), sheetName)
-
-			// Print each lesson (excluding time)
-			for _, l := range sheetLessons {
-				log.Printf("   ➡️ Grade: %d%s | Day: %d | Name: %s | Group: %s | Teacher: %s | Class: %s",
-					l.Grade,
-					l.GradeLetter,
-					l.LessonDay,
-					l.LessonName,
-					l.LessonGroup,
-					l.LessonTeacher,
-					l.LessonClass,
-				)
+	report.Lessons = len(lessons)
+	log.Printf("🎉 Finished parsing. Total lessons: %d (%d warnings, %d errors)\n",
+		len(lessons), report.Count(SeverityWarning), report.Count(SeverityError))
+
+	if len(lessons) == 0 {
+		return nil, report, report.problem(ErrEmptyWorkbook, SeverityError, "", "", "", "no lessons found in %d parsed sheets", len(report.Sheets))
+	}
+	if p.Strict {
+		if problems := report.Problems(); len(problems) > 0 {
+			errs := make([]error, len(problems))
+			for i, pe := range problems {
+				errs[i] = pe
 			}
-			if err := db.SaveLessons(context.Background(), sheetLessons); err != nil {
-				return nil, fmt.Errorf("error saving lessons from sheet %s: %w", sheetName, err)
+			return nil, report, errors.Join(errs...)
+		}
+	}
+	return lessons, report, nil
+}
+
+type sheetJob struct {
+	sheet    string
+	parallel int
+}
+
+type sheetResult struct {
+	lessons []lesson.Lesson
+	report  *ParseReport
+	err     error
+}
+
+// parseSheets parses the sheets concurrently, at most Workers at a time.
+// Every sheet collects diagnostics in its own report, so merging the results
+// in order gives the same report as parsing one sheet after another.
+func (p *Parser) parseSheets(ctx context.Context, f *excelize.File, source string, jobs []sheetJob) []sheetResult {
+	workers := p.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	results := make([]sheetResult, len(jobs))
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i, job := range jobs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			res := &results[i]
+			res.report = newReport(source)
+			if res.err = ctx.Err(); res.err != nil {
+				return
 			}
 
-			lessons = append(lessons, sheetLessons...)
-		}
+			log.Println("➡️ Parsing sheet:", job.sheet)
+			layout := *p.Layout // detection may adjust it for this sheet
+			sp := &sheetParser{f: f, sheet: job.sheet, parser: p, layout: &layout, report: res.report}
+			res.lessons, res.err = sp.parse()
+		}()
 	}
+	wg.Wait()
+	return results
+}
 
-	log.Printf("🎉 Finished parsing. Total lessons: %d\n", len(lessons))
-	return lessons, nil
+// gradeHeader is a class column such as 12A.
+type gradeHeader struct {
+	Grade  int
+	Letter string
 }
 
-func parseSheet(f *excelize.File, sheetName string) ([]lesson.Lesson, error) {
-	var lessons []lesson.Lesson
+// sheetRow is what the parser keeps of a row below the header.
+type sheetRow struct {
+	num      int    // 1-based
+	dayLabel string // value in the layout's DayColumn
+	period   string // value in the layout's PeriodColumn
+	cells    []sheetCell
+}
+
+// sheetCell is a non-empty cell in a lesson column.
+type sheetCell struct {
+	col   int // 1-based
+	value string
+}
+
+// mergedRange is a merged block of cells; it is indexed by its top-left axis.
+type mergedRange struct {
+	axis                               string // "C3:E3"
+	startCol, startRow, endCol, endRow int
+}
+
+// sheetParser holds the state of parsing a single sheet.
+type sheetParser struct {
+	f      *excelize.File
+	sheet  string
+	parser *Parser
+	layout *Layout
+	report *ParseReport
+
+	header       []string
+	dayCell      string
+	days         map[int]int    // 1-based row → weekday
+	times        map[int]string // 1-based row → raw time cell
+	periods      map[int]int    // 1-based row → period number
+	colToGrade   map[string]gradeHeader
+	disabledCols map[string]bool
+	merged       map[string]mergedRange
+	styles       map[int]styleAttributes
+}
 
-	lessonDay, err := f.GetCellValue(sheetName, "A1")
+func (sp *sheetParser) parse() ([]lesson.Lesson, error) {
+	rows, headerFound, err := sp.scan()
 	if err != nil {
 		return nil, err
 	}
-	log.Println("📅 Lesson day (A1):", lessonDay)
+	if !headerFound {
+		sp.report.problem(ErrUnknownGradeHeader, SeverityError, sp.sheet, "", "", "header row %d is past the end of the sheet", sp.layout.HeaderRow)
+		return nil, nil
+	}
 
-	colToGrade := make(map[string][2]string)
+	sp.mapHeader()
 
-	rows, err := f.GetRows(sheetName)
-	if err != nil {
-		return nil, err
+	if !sp.resolveDays(rows) {
+		sp.report.problem(ErrUnknownDay, SeverityError, sp.sheet, "", "", "could not determine the day; sheet rejected")
+		return nil, nil
 	}
 
-	for rowIndex, row := range rows {
-		for colIndex, cellValue := range row {
-			colName, _ := excelize.ColumnNumberToName(colIndex + 1)
-
-			// First row → header
-			if rowIndex == 0 {
-				if colName == "A" || colName == "B" || cellValue == "" {
-					continue
-				}
-				runes := []rune(cellValue)
-				gradeLetter := string(runes[len(runes)-1])
-				gradeNumber := string(runes[:len(runes)-1])
-				colToGrade[colName] = [2]string{gradeNumber, gradeLetter}
-				log.Printf("📌 Found grade header: %s%s at col %s\n", gradeNumber, gradeLetter, colName)
-				continue
-			}
+	sp.numberPeriods(rows)
 
-			if colName == "A" || colName == "B" {
+	if err := sp.indexMerged(); err != nil {
+		return nil, err
+	}
+
+	var lessons []lesson.Lesson
+	for _, row := range rows {
+		for _, cell := range row.cells {
+			axis, _ := excelize.CoordinatesToCellName(cell.col, row.num)
+			if mr, ok := sp.merged[axis]; ok {
+				lessons = append(lessons, sp.mergedLessons(mr, cell.value)...)
 				continue
 			}
 
-			lesson, ok := parseRowLesson(f, sheetName, rowIndex, colName, cellValue, colToGrade, lessonDay)
-			if ok {
+			colName, _ := excelize.ColumnNumberToName(cell.col)
+			for _, lesson := range sp.buildLessons(axis, colName, row.num, row.num, cell.value) {
 				lessons = append(lessons, lesson)
-				log.Printf("✅ Parsed lesson: %s (%s) at row %d col %s\n", lesson.LessonName, lesson.LessonGroup, rowIndex+1, colName)
-			} else {
-				if strings.TrimSpace(cellValue) != "" {
-					log.Printf("⚠️ Skipped cell at row %d col %s (value: %q)\n", rowIndex+1, colName, cellValue)
-				}
+				log.Printf("✅ Parsed lesson: %s (%s) at row %d col %s\n", lesson.LessonName, lesson.LessonGroup, row.num, colName)
 			}
 		}
 	}
 
-	mergedLessons, err := parseMergedLessons(f, sheetName, colToGrade, lessonDay)
+	return lessons, nil
+}
+
+// scan reads the sheet in a single pass with excelize's row iterator, so no
+// [][]string grid is built and walked again. It does not lower peak memory:
+// GetMergeCells and GetCellStyle load the whole worksheet regardless.
+//
+// It keeps the header row and the day cell, and for every row below the
+// header its day label, time, period and lesson cells. Empty rows are dropped.
+func (sp *sheetParser) scan() (rows []sheetRow, headerFound bool, err error) {
+	it, err := sp.f.Rows(sp.sheet)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
-	lessons = append(lessons, mergedLessons...)
+	defer it.Close()
 
-	return lessons, nil
-}
+	sp.times = make(map[int]string)
+	handle := func(num int, cols []string) {
+		if num == sp.layout.dayCellRow {
+			sp.dayCell = cellAt(cols, sp.layout.dayCellCol)
+		}
+		if num < sp.layout.HeaderRow {
+			return
+		}
+		if num == sp.layout.HeaderRow {
+			sp.header, headerFound = cols, true
+			return
+		}
 
-func parseRowLesson(
-	f *excelize.File,
-	sheetName string,
-	rowIndex int,
-	colName string,
-	cellValue string,
-	colToGrade map[string][2]string,
-	lessonDay string,
-) (lesson.Lesson, bool) {
-	lessonDetails := strings.Split(cellValue, "\n")
-	if len(lessonDetails) == 0 || strings.TrimSpace(lessonDetails[0]) == "" {
-		return lesson.Lesson{}, false
+		row := sheetRow{
+			num:      num,
+			dayLabel: cellAt(cols, sp.layout.dayColumn),
+			period:   cellAt(cols, sp.layout.periodColumn),
+		}
+		if t := cellAt(cols, sp.layout.timeColumn); t != "" {
+			sp.times[num] = t
+		}
+		for i, value := range cols {
+			if sp.layout.isLessonColumn(i+1) && strings.TrimSpace(value) != "" {
+				row.cells = append(row.cells, sheetCell{col: i + 1, value: value})
+			}
+		}
+		if row.dayLabel != "" || row.period != "" || sp.times[num] != "" || len(row.cells) > 0 {
+			rows = append(rows, row)
+		}
 	}
 
-	timeCell, _ := f.GetCellValue(sheetName, fmt.Sprintf("B%d", rowIndex+1), excelize.Options{RawCellValue: true})
-	timeParts := strings.Split(timeCell, "-")
-	start, end := "", ""
-	if len(timeParts) == 2 {
-		start = strings.TrimSpace(timeParts[0])
-		end = strings.TrimSpace(timeParts[1])
+	// With auto-detection the top rows are held back until the header row
+	// and time column are known.
+	var top [][]string
+	detecting := sp.layout.AutoDetect
+	flush := func() {
+		sp.detect(top)
+		for i, cols := range top {
+			handle(i+1, cols)
+		}
+		top, detecting = nil, false
 	}
 
-	if start == "" || end == "" {
-		log.Printf("⏭️ Skipping row %d col %s: missing time (%q)\n", rowIndex+1, colName, timeCell)
-		return lesson.Lesson{}, false
+	for num := 1; it.Next(); num++ {
+		cols, err := it.Columns(excelize.Options{RawCellValue: true})
+		if err != nil {
+			return nil, false, err
+		}
+		if detecting {
+			if top = append(top, cols); len(top) == detectRows {
+				flush()
+			}
+			continue
+		}
+		handle(num, cols)
 	}
+	if detecting {
+		flush()
+	}
+	return rows, headerFound, it.Error()
+}
 
-	gradeParts, ok := colToGrade[colName]
-	if !ok {
-		log.Printf("⏭️ Skipping row %d col %s: no grade mapping\n", rowIndex+1, colName)
-		return lesson.Lesson{}, false
+// cellAt returns the trimmed value of the 1-based column col, or "".
+func cellAt(cols []string, col int) string {
+	if col < 1 || col > len(cols) {
+		return ""
+	}
+	return strings.TrimSpace(cols[col-1])
+}
+
+// mapHeader reads the grade of every lesson column from the header row.
+func (sp *sheetParser) mapHeader() {
+	sp.colToGrade = make(map[string]gradeHeader)
+	sp.disabledCols = make(map[string]bool)
+
+	for colIndex, cellValue := range sp.header {
+		colName, _ := excelize.ColumnNumberToName(colIndex + 1)
+		cellValue = strings.TrimSpace(cellValue)
+		if !sp.layout.isLessonColumn(colIndex+1) || cellValue == "" {
+			continue
+		}
+		axis := colName + strconv.Itoa(sp.layout.HeaderRow)
+
+		grade, letter, locale, ok := sp.layout.parseGradeHeader(cellValue)
+		if !ok {
+			sp.report.problem(ErrUnknownGradeHeader, SeverityWarning, sp.sheet, axis, cellValue, "unrecognized grade header")
+			continue
+		}
+		sp.report.noteLocale(sp.sheet, locale)
+
+		if !sp.parser.wantsParallel(grade) {
+			sp.report.parallel(grade).Disabled = true
+			sp.report.info(sp.sheet, axis, cellValue, "column skipped: parallel %d is disabled", grade)
+			sp.disabledCols[colName] = true
+			continue
+		}
+		sp.colToGrade[colName] = gradeHeader{Grade: grade, Letter: letter}
+		log.Printf("📌 Found grade header: %d%s at col %s\n", grade, letter, colName)
 	}
+}
 
-	gradeNumber, err := strconv.Atoi(gradeParts[0])
+// indexMerged maps the merged ranges of the sheet by their top-left axis so
+// the row pass can handle a merged cell when it reaches its anchor.
+func (sp *sheetParser) indexMerged() error {
+	cells, err := sp.f.GetMergeCells(sp.sheet)
 	if err != nil {
-		log.Printf("❌ Invalid grade number %q at row %d col %s\n", gradeParts[0], rowIndex+1, colName)
-		return lesson.Lesson{}, false
+		return err
 	}
-	gradeLetter := gradeParts[1]
 
-	lessonName := strings.TrimSpace(lessonDetails[0])
-	lessonGroup := ""
-	if idx := strings.Index(lessonName, "№"); idx != -1 {
-		lessonGroup = strings.TrimSpace(lessonName[idx:])
-		lessonName = strings.TrimSpace(lessonName[:idx])
-		gradeLetter = ""
+	sp.merged = make(map[string]mergedRange, len(cells))
+	for _, mc := range cells {
+		startAxis, endAxis := mc.GetStartAxis(), mc.GetEndAxis()
+		startCol, startRow, err := excelize.CellNameToCoordinates(startAxis)
+		if err != nil {
+			return err
+		}
+		endCol, endRow, err := excelize.CellNameToCoordinates(endAxis)
+		if err != nil {
+			return err
+		}
+		sp.merged[startAxis] = mergedRange{
+			axis:     startAxis + ":" + endAxis,
+			startCol: startCol,
+			startRow: startRow,
+			endCol:   endCol,
+			endRow:   endRow,
+		}
 	}
+	return nil
+}
 
-	startTime, err := time.Parse("15:04", start)
-	if err != nil {
-		log.Printf("❌ Invalid start time %q at row %d col %s\n", start, rowIndex+1, colName)
-		return lesson.Lesson{}, false
+// mergedLessons handles a merged cell. A range spanning several rows is a
+// double period; one spanning several class columns is one shared lesson
+// for each of those classes.
+func (sp *sheetParser) mergedLessons(mr mergedRange, val string) []lesson.Lesson {
+	axis := mr.axis
+
+	var cols []string
+	for col := mr.startCol; col <= mr.endCol; col++ {
+		colName, _ := excelize.ColumnNumberToName(col)
+		if _, ok := sp.colToGrade[colName]; ok {
+			cols = append(cols, colName)
+		}
 	}
-	endTime, err := time.Parse("15:04", end)
-	if err != nil {
-		log.Printf("❌ Invalid end time %q at row %d col %s\n", end, rowIndex+1, colName)
-		return lesson.Lesson{}, false
+	if len(cols) == 0 {
+		colName, _ := excelize.ColumnNumberToName(mr.startCol)
+		cols = []string{colName}
 	}
-	startTime = time.Date(2000, 1, 1, startTime.Hour(), startTime.Minute(), 0, 0, time.UTC)
-	endTime   = time.Date(2000, 1, 1, endTime.Hour(), endTime.Minute(), 0, 0, time.UTC)
 
-	log.Printf("From %s:%s to %s:%s", start, end, startTime, endTime)
+	// Each subgroup of the cell forms its own stream across the classes.
+	var rangeLessons []lesson.Lesson
+	var streamKeys []string
+	streams := make(map[string][]int)
+	seen := make(map[string]bool)
+	for _, colName := range cols {
+		for _, lessonObj := range sp.buildLessons(axis, colName, mr.startRow, mr.endRow, val) {
+			// Group lessons drop the letter, so several columns collapse into one
+			streamKey := canonicalText(lessonObj.LessonName) + "|" + canonicalText(lessonObj.LessonGroup)
+			class := fmt.Sprintf("%d%s|%s", lessonObj.Grade, lessonObj.GradeLetter, streamKey)
+			if seen[class] {
+				continue
+			}
+			seen[class] = true
 
-	lessonObj := lesson.Lesson{
-		Grade:         gradeNumber,
-		GradeLetter:   gradeLetter,
-		LessonDay:     parseDayToIndex(lessonDay),
-		LessonStart:   startTime,
-		LessonEnd:     endTime,
-		LessonName:    lessonName,
-		LessonTeacher: "",
-		LessonClass:   "",
-		LessonGroup:   lessonGroup,
+			if _, ok := streams[streamKey]; !ok {
+				streamKeys = append(streamKeys, streamKey)
+			}
+			streams[streamKey] = append(streams[streamKey], len(rangeLessons))
+			rangeLessons = append(rangeLessons, lessonObj)
+		}
 	}
 
-	if len(lessonDetails) >= 2 {
-		lessonObj.LessonTeacher = strings.TrimSpace(lessonDetails[1])
-	}
-	if len(lessonDetails) >= 3 {
-		lessonObj.LessonClass = strings.TrimSpace(lessonDetails[2])
+	for n, key := range streamKeys {
+		members := streams[key]
+		if len(members) < 2 {
+			continue
+		}
+		streamID := sp.sheet + "!" + axis
+		if len(streamKeys) > 1 {
+			streamID += fmt.Sprintf("#%d", n+1)
+		}
+		for _, i := range members {
+			rangeLessons[i].StreamID = streamID
+		}
+		sp.report.info(sp.sheet, axis, val, "shared lesson for %d classes", len(members))
 	}
 
-	return lessonObj, true
+	for _, lessonObj := range rangeLessons {
+		log.Printf("✅ Parsed merged lesson: %s (%s) for %d%s at %s\n", lessonObj.LessonName, lessonObj.LessonGroup, lessonObj.Grade, lessonObj.GradeLetter, axis)
+	}
+	return rangeLessons
 }
 
-// parseMergedLessons handles merged cells
-func parseMergedLessons(
-	f *excelize.File,
-	sheetName string,
-	colToGrade map[string][2]string,
-	lessonDay string,
-) ([]lesson.Lesson, error) {
-	var lessons []lesson.Lesson
-	skipped := 0
+var errMissingTime = errors.New("missing time")
 
-	mergedCells, err := f.GetMergeCells(sheetName)
-	if err != nil {
-		return nil, err
+// rowTime reads the time range of a 1-based row. When the time cell is
+// empty, or holds only the start, the bell schedule fills in the rest for
+// the row's period.
+func (sp *sheetParser) rowTime(row int) (start, end time.Time, err error) {
+	timeAxis, _ := excelize.CoordinatesToCellName(sp.layout.timeColumn, row)
+	timeCell := sp.times[row]
+
+	start, end, hasEnd, err := parseTimeRange(timeCell)
+	if err == nil && hasEnd {
+		return start, end, nil
 	}
 
-	for _, mc := range mergedCells {
-		val := mc.GetCellValue()
-		if val == "" {
-			skipped++
-			log.Printf("⚠️ Skipped merged cell %s-%s: empty value\n", mc.GetStartAxis(), mc.GetEndAxis())
+	period := sp.periods[row]
+	bell, ok := sp.layout.bells[period]
+	switch {
+	case err == nil && ok:
+		return start, bell.End, nil
+	case err == nil:
+		return start, end, fmt.Errorf("no end time in %s (%q) and no bell schedule for period %d", timeAxis, timeCell, period)
+	case errors.Is(err, errMissingTime) && ok:
+		return bell.Start, bell.End, nil
+	case errors.Is(err, errMissingTime):
+		return start, end, fmt.Errorf("%w in %s (%q)", errMissingTime, timeAxis, timeCell)
+	}
+	return start, end, fmt.Errorf("%v in %s", err, timeAxis)
+}
+
+// numberPeriods numbers the rows below the header, so a lesson can record
+// which periods it covers. With a period column the numbers are read from
+// it; otherwise rows that carry a time are counted, restarting with each day.
+func (sp *sheetParser) numberPeriods(rows []sheetRow) {
+	sp.periods = make(map[int]int)
+	period, day := 0, 0
+	for _, row := range rows {
+		if d := sp.days[row.num]; d != day {
+			period, day = 0, d
+		}
+		if sp.layout.periodColumn > 0 {
+			if m := leadingNumber.FindStringSubmatch(row.period); m != nil {
+				n, _ := strconv.Atoi(m[1])
+				sp.periods[row.num] = n
+			}
 			continue
 		}
+		if sp.times[row.num] != "" {
+			period++
+			sp.periods[row.num] = period
+		}
+	}
+}
 
-		startAxis := mc.GetStartAxis()
-		endAxis := mc.GetEndAxis()
+// buildLessons turns the contents of a lesson cell into lessons, taking the
+// grade from colName's header. A cell holding several subgroups yields one
+// lesson per subgroup. A cell spanning several rows (a double period) starts
+// when the first row starts and ends when the last row ends.
+func (sp *sheetParser) buildLessons(axis, colName string, startRow, endRow int, cellValue string) []lesson.Lesson {
+	if sp.disabledCols[colName] {
+		return nil
+	}
 
-		// Extract column and row numbers
-		startCol := strings.TrimRightFunc(startAxis, func(r rune) bool {
-			return r >= '0' && r <= '9'
-		})
-		endRow := strings.TrimLeftFunc(endAxis, func(r rune) bool {
-			return r < '0' || r > '9'
-		})
+	day := sp.days[startRow]
+	if day == 0 {
+		sp.report.problem(ErrUnknownDay, SeverityWarning, sp.sheet, axis, cellValue, "no day label above row %d", startRow)
+		return nil
+	}
 
-		lessonDetails := strings.Split(val, "\n")
-		if len(lessonDetails) == 0 || strings.TrimSpace(lessonDetails[0]) == "" {
-			skipped++
-			log.Printf("⚠️ Skipped merged cell %s-%s: no lesson details\n", startAxis, endAxis)
-			continue
+	startTime, endTime, err := sp.rowTime(startRow)
+	if err == nil && endRow != startRow {
+		_, endTime, err = sp.rowTime(endRow)
+	}
+	if err != nil {
+		if errors.Is(err, errMissingTime) {
+			sp.report.problem(ErrBadTimeRange, SeverityWarning, sp.sheet, axis, cellValue, "%v", err)
+		} else {
+			sp.report.problem(ErrBadTimeRange, SeverityError, sp.sheet, axis, cellValue, "%v", err)
 		}
+		return nil
+	}
 
-		// Get time from B<endRow>
-		timeCell, _ := f.GetCellValue(sheetName, "B"+endRow, excelize.Options{RawCellValue: true})
-		timeParts := strings.Split(timeCell, "-")
-		start, end := "", ""
-		if len(timeParts) == 2 {
-			start = strings.TrimSpace(timeParts[0])
-			end = strings.TrimSpace(timeParts[1])
-		}
+	header, ok := sp.colToGrade[colName]
+	if !ok {
+		sp.report.problem(ErrUnknownGradeHeader, SeverityWarning, sp.sheet, axis, cellValue, "no grade mapping for column %s", colName)
+		return nil
+	}
 
-		if start == "" || end == "" {
-			skipped++
-			log.Printf("⚠️ Skipped merged cell %s-%s: missing time (%q)\n", startAxis, endAxis, timeCell)
-			continue
-		}
+	anchor, _, _ := strings.Cut(axis, ":")
+	attrs, err := sp.cellStyle(anchor)
+	if err != nil {
+		sp.report.warn(sp.sheet, axis, cellValue, "failed to read cell style: %v", err)
+	}
 
-		gradeParts, ok := colToGrade[startCol]
-		if !ok {
-			skipped++
-			log.Printf("⚠️ Skipped merged cell %s-%s: no grade mapping for col %s\n", startAxis, endAxis, startCol)
-			continue
-		}
+	blocks := sp.layout.cellBlocks(cellValue)
+	if len(blocks) > 1 {
+		sp.report.info(sp.sheet, axis, cellValue, "cell split into %d lessons", len(blocks))
+	}
 
-		gradeNumber, err := strconv.Atoi(gradeParts[0])
-		if err != nil {
-			skipped++
-			log.Printf("❌ Invalid grade number %q at merged cell %s-%s\n", gradeParts[0], startAxis, endAxis)
+	var lessons []lesson.Lesson
+	for _, block := range blocks {
+		lessonName, teacher, room, uncertain := sp.layout.cellDetails(block)
+		if lessonName == "" {
+			sp.report.warn(sp.sheet, axis, block, "cell has no lesson name")
 			continue
 		}
-		gradeLetter := gradeParts[1]
+		for _, note := range uncertain {
+			sp.report.warn(sp.sheet, axis, block, "%s", note)
+		}
 
-		lessonName := strings.TrimSpace(lessonDetails[0])
+		gradeLetter := header.Letter
 		lessonGroup := ""
 		if idx := strings.Index(lessonName, "№"); idx != -1 {
 			lessonGroup = strings.TrimSpace(lessonName[idx:])
@@ -318,67 +622,22 @@ func parseMergedLessons(
 			gradeLetter = "" // group lessons are for the whole parallel
 		}
 
-		startTime, err := time.Parse("15:04", start)
-		if err != nil {
-			skipped++
-			log.Printf("❌ Invalid start time %q at merged cell %s-%s\n", start, startAxis, endAxis)
-			continue
-		}
-		endTime, err := time.Parse("15:04", end)
-		if err != nil {
-			skipped++
-			log.Printf("❌ Invalid end time %q at merged cell %s-%s\n", end, startAxis, endAxis)
-			continue
-		}
-		startTime = time.Date(2000, 1, 1, startTime.Hour(), startTime.Minute(), 0, 0, time.UTC)
-		endTime   = time.Date(2000, 1, 1, endTime.Hour(), endTime.Minute(), 0, 0, time.UTC)
-
-		lessonObj := lesson.Lesson{
-			Grade:         gradeNumber,
+		lessons = append(lessons, lesson.Lesson{
+			Grade:         header.Grade,
 			GradeLetter:   gradeLetter,
-			LessonDay:     parseDayToIndex(lessonDay),
+			LessonDay:     day,
+			Period:        sp.periods[startRow],
+			PeriodEnd:     sp.periods[endRow],
 			LessonStart:   startTime,
 			LessonEnd:     endTime,
 			LessonName:    lessonName,
-			LessonTeacher: "",
-			LessonClass:   "",
+			LessonTeacher: teacher,
+			LessonClass:   room,
 			LessonGroup:   lessonGroup,
-		}
-
-		if len(lessonDetails) >= 2 {
-			lessonObj.LessonTeacher = strings.TrimSpace(lessonDetails[1])
-		}
-		if len(lessonDetails) >= 3 {
-			lessonObj.LessonClass = strings.TrimSpace(lessonDetails[2])
-		}
-
-		lessons = append(lessons, lessonObj)
-		log.Printf("✅ Parsed merged lesson: %s (%s) at %s-%s\n with %s : %s from %s : %s row %s", lessonObj.LessonName, lessonObj.LessonGroup, startAxis, endAxis, start, end, startTime, endTime, endRow)
+			Status:        attrs.Status,
+			Format:        attrs.Format,
+			Building:      attrs.Building,
+		})
 	}
-
-	log.Printf("📊 Finished merged cells in %s: %d lessons, %d skipped\n", sheetName, len(lessons), skipped)
-	return lessons, nil
+	return lessons
 }
-
-func parseDayToIndex(day string) int {
-	day = strings.ToLower(strings.TrimSpace(day))
-	switch {
-	case strings.Contains(day, "понедельник"), strings.Contains(day, "mon"):
-		return 1
-	case strings.Contains(day, "вторник"), strings.Contains(day, "tue"):
-		return 2
-	case strings.Contains(day, "среда"), strings.Contains(day, "wed"):
-		return 3
-	case strings.Contains(day, "четверг"), strings.Contains(day, "thu"):
-		return 4
-	case strings.Contains(day, "пятница"), strings.Contains(day, "fri"):
-		return 5
-	case strings.Contains(day, "суббота"), strings.Contains(day, "sat"):
-		return 6
-	case strings.Contains(day, "воскресенье"), strings.Contains(day, "sun"):
-		return 7
-	default:
-		log.Printf("⚠️ Unknown day string: %q → returning 0", day)
-		return 0
-	}
-}
\ No newline at end of file
@@ -0,0 +1,206 @@
+package excel
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+
+	"github.com/in-nis/cnis-back/internal/models"
+)
+
+// testSheet is one sheet of a workbook built for a test.
+type testSheet struct {
+	name   string
+	cells  map[string]any // axis → value
+	merges [][2]string    // top-left and bottom-right axes
+	styles map[string]*excelize.Style
+}
+
+// testWorkbook writes sheets into an in-memory xlsx file.
+func testWorkbook(tb testing.TB, sheets ...testSheet) UploadSource {
+	tb.Helper()
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	for i, sh := range sheets {
+		var err error
+		if i == 0 {
+			err = f.SetSheetName("Sheet1", sh.name)
+		} else {
+			_, err = f.NewSheet(sh.name)
+		}
+		if err != nil {
+			tb.Fatal(err)
+		}
+		for axis, v := range sh.cells {
+			if err := f.SetCellValue(sh.name, axis, v); err != nil {
+				tb.Fatal(err)
+			}
+		}
+		for _, m := range sh.merges {
+			if err := f.MergeCell(sh.name, m[0], m[1]); err != nil {
+				tb.Fatal(err)
+			}
+		}
+		for axis, style := range sh.styles {
+			id, err := f.NewStyle(style)
+			if err != nil {
+				tb.Fatal(err)
+			}
+			if err := f.SetCellStyle(sh.name, axis, axis, id); err != nil {
+				tb.Fatal(err)
+			}
+		}
+	}
+
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return UploadSource{Name: "test.xlsx", Data: buf.Bytes()}
+}
+
+// parseTest parses sheets with p, or the default parser if p is nil.
+func parseTest(tb testing.TB, p *Parser, sheets ...testSheet) ([]models.Lesson, *ParseReport, error) {
+	tb.Helper()
+	if p == nil {
+		p = NewParser(nil)
+	}
+
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	return p.Parse(context.Background(), testWorkbook(tb, sheets...))
+}
+
+// mustParse is parseTest for workbooks that are expected to parse.
+func mustParse(tb testing.TB, p *Parser, sheets ...testSheet) ([]models.Lesson, *ParseReport) {
+	tb.Helper()
+	lessons, report, err := parseTest(tb, p, sheets...)
+	if err != nil {
+		tb.Fatalf("parse failed: %v", err)
+	}
+	return lessons, report
+}
+
+// findLesson returns the lesson of class (e.g. "12A") named name.
+func findLesson(tb testing.TB, lessons []models.Lesson, class, name string) models.Lesson {
+	tb.Helper()
+	for _, l := range lessons {
+		if fmt.Sprintf("%d%s", l.Grade, l.GradeLetter) == class && l.LessonName == name {
+			return l
+		}
+	}
+	tb.Fatalf("no lesson %q for %s among %d lessons", name, class, len(lessons))
+	return models.Lesson{}
+}
+
+// testLayout returns a compiled copy of the default layout changed by edit.
+func testLayout(tb testing.TB, edit func(l *Layout)) *Layout {
+	tb.Helper()
+	l := DefaultLayout()
+	edit(l)
+	if err := l.compile(); err != nil {
+		tb.Fatal(err)
+	}
+	return l
+}
+
+// schoolWorkbook builds a timetable the size of a whole school: grades 7 to
+// 12 on their own sheets, eight classes each, six days of eight periods,
+// with subgroup cells and lessons merged across classes.
+func schoolWorkbook(tb testing.TB) []byte {
+	tb.Helper()
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	days := []string{"Понедельник", "Вторник", "Среда", "Четверг", "Пятница", "Суббота"}
+	for grade := 7; grade <= 12; grade++ {
+		sheet := fmt.Sprintf("%d классы", grade)
+		if _, err := f.NewSheet(sheet); err != nil {
+			tb.Fatal(err)
+		}
+
+		set := func(axis string, value any) {
+			if err := f.SetCellValue(sheet, axis, value); err != nil {
+				tb.Fatal(err)
+			}
+		}
+		for c := 0; c < 8; c++ {
+			col, _ := excelize.ColumnNumberToName(c + 3)
+			set(col+"1", fmt.Sprintf("%d%c", grade, 'A'+c))
+		}
+
+		row := 2
+		for _, day := range days {
+			set(fmt.Sprintf("A%d", row), day)
+			for p := 0; p < 8; p++ {
+				set(fmt.Sprintf("B%d", row), fmt.Sprintf("%02d:00-%02d:45", 8+p, 8+p))
+				for c := 0; c < 8; c++ {
+					col, _ := excelize.ColumnNumberToName(c + 3)
+					value := fmt.Sprintf("Предмет %d\nИванов И.И.\n%d", p, 100*(p%4+1)+c)
+					if p == 5 {
+						value = fmt.Sprintf("Англ №1 / Англ №2\nSmith J. / Brown K.\n%d / %d", 200+c, 210+c)
+					}
+					set(fmt.Sprintf("%s%d", col, row), value)
+				}
+				if p == 3 {
+					if err := f.MergeCell(sheet, fmt.Sprintf("C%d", row), fmt.Sprintf("E%d", row)); err != nil {
+						tb.Fatal(err)
+					}
+				}
+				row++
+			}
+		}
+	}
+	if err := f.DeleteSheet("Sheet1"); err != nil {
+		tb.Fatal(err)
+	}
+
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// BenchmarkParse compares parsing the sheets one at a time with parsing them
+// concurrently. The concurrent run only pulls ahead with more than one CPU,
+// e.g. go test -bench Parse -cpu 1,4.
+func BenchmarkParse(b *testing.B) {
+	src := UploadSource{Name: "school.xlsx", Data: schoolWorkbook(b)}
+
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	for _, bm := range []struct {
+		name    string
+		workers int
+	}{
+		{"sequential", 1},
+		{"concurrent", 0}, // GOMAXPROCS
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			p := NewParser(nil)
+			p.Workers = bm.workers
+
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				lessons, _, err := p.Parse(context.Background(), src)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(lessons) == 0 {
+					b.Fatal("no lessons parsed")
+				}
+			}
+		})
+	}
+}
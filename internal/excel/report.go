@@ -0,0 +1,140 @@
+package excel
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+type Severity string
+
+const (
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+// Diagnostic describes one problem (or notable decision) at a workbook cell.
+type Diagnostic struct {
+	Sheet    string   `json:"sheet"`
+	Axis     string   `json:"axis,omitempty"` // "C5", or "C5:D6" for merged ranges
+	Value    string   `json:"value,omitempty"`
+	Severity Severity `json:"severity"`
+	Code     string   `json:"code,omitempty"` // set for known problems, see ParseError
+	Reason   string   `json:"reason"`
+}
+
+// ParallelStats summarises one grade parallel of an import.
+type ParallelStats struct {
+	Sheets   []string `json:"sheets"`
+	Lessons  int      `json:"lessons"`
+	Warnings int      `json:"warnings"`
+	Errors   int      `json:"errors"`
+	Disabled bool     `json:"disabled,omitempty"` // switched off by layout or admin
+}
+
+// ParseReport is everything the parser has to say about one workbook.
+type ParseReport struct {
+	Source      string                 `json:"source"`
+	StartedAt   time.Time              `json:"started_at"`
+	FinishedAt  time.Time              `json:"finished_at"`
+	Sheets      []string               `json:"sheets"`
+	Lessons     int                    `json:"lessons"`
+	Parallels   map[int]*ParallelStats `json:"parallels"`
+	Locales     map[string][]string    `json:"locales"` // sheet → locales its day and header words matched
+	Layouts     map[string]SheetLayout `json:"layouts"` // sheet → where its header row and time column were found
+	Diagnostics []Diagnostic           `json:"diagnostics"`
+}
+
+func newReport(source string) *ParseReport {
+	return &ParseReport{
+		Source:    source,
+		StartedAt: time.Now(),
+		Parallels: make(map[int]*ParallelStats),
+		Locales:   make(map[string][]string),
+		Layouts:   make(map[string]SheetLayout),
+	}
+}
+
+// merge adds the findings of a report collected for one sheet on its own.
+func (r *ParseReport) merge(sheet *ParseReport) {
+	r.Diagnostics = append(r.Diagnostics, sheet.Diagnostics...)
+	for name, locales := range sheet.Locales {
+		for _, l := range locales {
+			r.noteLocale(name, l)
+		}
+	}
+	for name, layout := range sheet.Layouts {
+		r.Layouts[name] = layout
+	}
+	for grade, ps := range sheet.Parallels {
+		if ps.Disabled {
+			r.parallel(grade).Disabled = true
+		}
+	}
+}
+
+// noteLocale records that a word on sheet matched locale.
+func (r *ParseReport) noteLocale(sheet, locale string) {
+	if locale == "" {
+		return
+	}
+	for _, l := range r.Locales[sheet] {
+		if l == locale {
+			return
+		}
+	}
+	r.Locales[sheet] = append(r.Locales[sheet], locale)
+}
+
+// parallel returns the stats entry for grade, creating it on first use.
+func (r *ParseReport) parallel(grade int) *ParallelStats {
+	ps, ok := r.Parallels[grade]
+	if !ok {
+		ps = &ParallelStats{}
+		r.Parallels[grade] = ps
+	}
+	return ps
+}
+
+func (r *ParseReport) add(sev Severity, sheet, axis, value, format string, args ...interface{}) {
+	d := Diagnostic{
+		Sheet:    sheet,
+		Axis:     axis,
+		Value:    value,
+		Severity: sev,
+		Reason:   fmt.Sprintf(format, args...),
+	}
+	r.Diagnostics = append(r.Diagnostics, d)
+
+	if sev != SeverityInfo {
+		log.Printf("⚠️ [%s] %s!%s: %s (value: %q)\n", sev, sheet, axis, d.Reason, value)
+	}
+}
+
+func (r *ParseReport) info(sheet, axis, value, format string, args ...interface{}) {
+	r.add(SeverityInfo, sheet, axis, value, format, args...)
+}
+
+func (r *ParseReport) warn(sheet, axis, value, format string, args ...interface{}) {
+	r.add(SeverityWarning, sheet, axis, value, format, args...)
+}
+
+func (r *ParseReport) fail(sheet, axis, value, format string, args ...interface{}) {
+	r.add(SeverityError, sheet, axis, value, format, args...)
+}
+
+// Count returns how many diagnostics of the given severity were recorded.
+func (r *ParseReport) Count(sev Severity) int {
+	return countSeverity(r.Diagnostics, sev)
+}
+
+func countSeverity(diags []Diagnostic, sev Severity) int {
+	n := 0
+	for _, d := range diags {
+		if d.Severity == sev {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,85 @@
+package excel
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/in-nis/cnis-back/internal/models"
+)
+
+var (
+	// "корпус 2, 105", "корп. 2 каб. 105", "блок B 12"
+	buildingPrefix = regexp.MustCompile(`(?i)^(?:корп(?:ус)?|блок|building|bldg)\.?\s*([\p{L}\d]{1,8})\s*[,;]?\s+(.+)$`)
+	// "каб. 301", "ауд 12", "room 5"
+	roomPrefix = regexp.MustCompile(`(?i)^(?:каб(?:инет)?|ауд(?:итория)?|бөлме|дәрісхана|room|rm)\.?\s*`)
+	// "2.105", "2/105": building and room
+	dottedRoom = regexp.MustCompile(`^(\d{1,2})\s*[./]\s*(\d{1,4}\p{L}?)$`)
+	// "Б - 204", "301 а"
+	roomSpacing = regexp.MustCompile(`\s*-\s*|(\d)\s+(\p{L})$`)
+	digits      = regexp.MustCompile(`\d+`)
+)
+
+// ParseRoom splits a room cell line into building and room number and
+// guesses the floor from the number: 301 and Б-204 are on floors 3 and 2.
+// building is the building already known from the cell's style; a building
+// named in the text wins. Rooms without a three or four digit number, such as
+// "Спортзал", have no floor.
+func ParseRoom(text, building string) (number, bldg string, floor *int) {
+	text = strings.Join(strings.Fields(text), " ")
+	if text == "" {
+		return "", building, nil
+	}
+
+	if m := buildingPrefix.FindStringSubmatch(text); m != nil {
+		building, text = m[1], m[2]
+	}
+	text = roomPrefix.ReplaceAllString(text, "")
+	if m := dottedRoom.FindStringSubmatch(text); m != nil {
+		building, text = m[1], m[2]
+	}
+	number = strings.ToUpper(roomSpacing.ReplaceAllStringFunc(text, func(s string) string {
+		if strings.Contains(s, "-") {
+			return "-"
+		}
+		return strings.Join(strings.Fields(s), "")
+	}))
+	if strings.IndexFunc(number, func(r rune) bool { return r >= '0' && r <= '9' }) < 0 {
+		number = text // a named room keeps its spelling
+	}
+
+	if d := digits.FindString(number); len(d) == 3 || len(d) == 4 {
+		n, _ := strconv.Atoi(d)
+		f := n / 100
+		floor = &f
+	}
+	return number, strings.ToUpper(strings.TrimSpace(building)), floor
+}
+
+// RoomKey identifies a room across spellings: "каб. 301", "301" and
+// "Каб 301" share a key, and so do "корпус 2, 105" and "2.105". Keys never
+// exceed maxKeyLen bytes.
+func RoomKey(text, building string) string {
+	number, bldg, _ := ParseRoom(text, building)
+	if number == "" {
+		return ""
+	}
+	return boundKey(canonicalText(bldg) + "|" + canonicalText(number))
+}
+
+// Rooms returns one Room per RoomKey of lessons, as stored by
+// db.PublishGeneration.
+func Rooms(lessons []models.Lesson) []models.Room {
+	seen := make(map[string]bool)
+	var rooms []models.Room
+	for _, l := range lessons {
+		if l.RoomKey == "" || seen[l.RoomKey] {
+			continue
+		}
+		seen[l.RoomKey] = true
+
+		number, building, floor := ParseRoom(l.LessonClass, l.Building)
+		rooms = append(rooms, models.Room{Key: l.RoomKey, Number: number, Building: building, Floor: floor})
+	}
+	return rooms
+}
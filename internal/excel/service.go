@@ -1 +1,207 @@
-package excel 
\ No newline at end of file
+package excel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+
+	"github.com/in-nis/cnis-back/internal/db"
+	"github.com/in-nis/cnis-back/internal/models"
+)
+
+// Service ties a configured schedule source and parser to the DB.
+type Service struct {
+	source ScheduleSource
+	parser *Parser
+
+	importing sync.Mutex // the cron job and the reload endpoint may overlap
+}
+
+func NewService(source ScheduleSource, parser *Parser) *Service {
+	return &Service{source: source, parser: parser}
+}
+
+func (s *Service) Source() ScheduleSource {
+	return s.source
+}
+
+// ImportOptions tune a single import.
+type ImportOptions struct {
+	// Force imports even a workbook identical to the last imported one.
+	Force bool
+	// Strict fails on any known parse problem, on top of Parser.Strict.
+	Strict bool
+}
+
+// Import parses the configured source and publishes the result as a new
+// schedule generation. Imports run one at a time. Nothing is written unless
+// the whole workbook parses. The parse report is stored either way. Parse
+// failures wrap ParseErrors.
+//
+// Unless opts.Force is set, a workbook identical to the last imported one is
+// not parsed at all and ErrNotModified is returned, as long as the parser
+// settings (layout and disabled parallels) are unchanged too. Every check is
+// written to the import log.
+func (s *Service) Import(ctx context.Context, opts ImportOptions) (*models.ScheduleGeneration, *ParseReport, error) {
+	s.importing.Lock()
+	defer s.importing.Unlock()
+
+	parser, err := s.currentParser(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	parser.Strict = parser.Strict || opts.Strict
+	fingerprint := parser.Fingerprint()
+
+	var prev FetchState
+	if !opts.Force {
+		last, err := db.GetLastImported(ctx, s.source.String())
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load import log: %w", err)
+		}
+		if last != nil && last.Fingerprint == fingerprint {
+			prev = FetchState{ETag: last.ETag, LastModified: last.LastModified, ContentHash: last.ContentHash}
+		} else if last != nil {
+			log.Println("🔧 Parser settings changed since the last import, re-importing")
+		}
+	}
+
+	data, state, err := s.fetch(ctx, prev)
+	if errors.Is(err, ErrNotModified) {
+		log.Printf("⏸️ Schedule from %s unchanged, skipping import\n", s.source)
+		s.logImport(ctx, models.ImportUnchanged, state, fingerprint, nil, "")
+		return nil, nil, err
+	}
+	if err != nil {
+		s.logImport(ctx, models.ImportFailed, state, fingerprint, nil, err.Error())
+		return nil, nil, fmt.Errorf("failed to fetch %s: %w", s.source, err)
+	}
+
+	lessons, report, err := parser.Parse(ctx, bufferedSource{name: s.source.String(), data: data})
+	if err != nil {
+		s.saveReport(ctx, report, nil)
+		s.logImport(ctx, models.ImportFailed, state, fingerprint, nil, err.Error())
+		return nil, report, fmt.Errorf("failed to parse %s: %w", s.source, err)
+	}
+
+	gen, err := db.PublishGeneration(ctx, s.source.String(), lessons, Teachers(lessons), Rooms(lessons))
+	if err != nil {
+		s.saveReport(ctx, report, nil)
+		s.logImport(ctx, models.ImportFailed, state, fingerprint, nil, err.Error())
+		return nil, report, fmt.Errorf("failed to publish schedule: %w", err)
+	}
+	s.saveReport(ctx, report, &gen.ID)
+	s.logImport(ctx, models.ImportImported, state, fingerprint, &gen.ID, "")
+
+	log.Printf("📦 Published schedule generation #%d with %d lessons\n", gen.ID, gen.LessonCount)
+	return gen, report, nil
+}
+
+// fetch reads the whole workbook from the source, returning ErrNotModified
+// when it matches prev.
+func (s *Service) fetch(ctx context.Context, prev FetchState) ([]byte, FetchState, error) {
+	var (
+		r     io.ReadCloser
+		state FetchState
+		err   error
+	)
+	if cs, ok := s.source.(ConditionalSource); ok {
+		r, state, err = cs.OpenIfChanged(ctx, prev)
+	} else {
+		r, err = s.source.Open(ctx)
+	}
+	if err != nil {
+		return nil, state, err
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, state, err
+	}
+
+	state.ContentHash = contentHash(data)
+	if prev.ContentHash != "" && state.ContentHash == prev.ContentHash {
+		return nil, state, ErrNotModified
+	}
+	return data, state, nil
+}
+
+// logImport writes one import log entry; failures are only logged.
+func (s *Service) logImport(ctx context.Context, status string, state FetchState, fingerprint string, generationID *uint, message string) {
+	entry := models.ImportLog{
+		Source:       s.source.String(),
+		Status:       status,
+		ContentHash:  state.ContentHash,
+		ETag:         state.ETag,
+		LastModified: state.LastModified,
+		Fingerprint:  fingerprint,
+		GenerationID: generationID,
+		Message:      message,
+	}
+	if err := db.SaveImportLog(ctx, &entry); err != nil {
+		log.Println("❌ Failed to save import log:", err)
+	}
+}
+
+// currentParser applies the admin's parallel switches to the configured parser.
+func (s *Service) currentParser(ctx context.Context) (*Parser, error) {
+	disabled, err := db.GetDisabledParallels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load parallel settings: %w", err)
+	}
+	return s.parser.WithDisabled(disabled), nil
+}
+
+// saveReport stores the report; failures are only logged so they never
+// mask the import result.
+func (s *Service) saveReport(ctx context.Context, report *ParseReport, generationID *uint) {
+	data, err := json.Marshal(report)
+	if err != nil {
+		log.Println("❌ Failed to encode parse report:", err)
+		return
+	}
+
+	row := models.ImportReport{
+		Source:       report.Source,
+		GenerationID: generationID,
+		Lessons:      report.Lessons,
+		Warnings:     report.Count(SeverityWarning),
+		Errors:       report.Count(SeverityError),
+		Report:       data,
+	}
+	if err := db.SaveImportReport(ctx, &row); err != nil {
+		log.Println("❌ Failed to save parse report:", err)
+	}
+}
+
+// Preview is the outcome of a dry-run import.
+type Preview struct {
+	Report *ParseReport `json:"report"`
+	ScheduleDiff
+}
+
+// Preview parses src without saving anything and diffs it against the live
+// schedule.
+func (s *Service) Preview(ctx context.Context, src ScheduleSource) (*Preview, error) {
+	parser, err := s.currentParser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	lessons, report, err := parser.Parse(ctx, src)
+	if err != nil {
+		return &Preview{Report: report}, fmt.Errorf("failed to parse %s: %w", src, err)
+	}
+
+	live, err := db.GetActiveLessons(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load live schedule: %w", err)
+	}
+
+	return &Preview{Report: report, ScheduleDiff: Diff(live, lessons)}, nil
+}
@@ -0,0 +1,207 @@
+package excel
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ScheduleSource is anything that can hand out a timetable workbook.
+type ScheduleSource interface {
+	// Open returns a reader over the xlsx workbook. The caller closes it.
+	Open(ctx context.Context) (io.ReadCloser, error)
+	// String describes the source for logs.
+	String() string
+}
+
+// ConditionalSource is a source that can tell on its own that the workbook
+// has not changed since prev, without handing out the bytes.
+type ConditionalSource interface {
+	ScheduleSource
+	// OpenIfChanged returns ErrNotModified when nothing changed.
+	OpenIfChanged(ctx context.Context, prev FetchState) (io.ReadCloser, FetchState, error)
+}
+
+// NewSource builds a source from a spec string:
+//
+//	sheet.xlsx, file:sheet.xlsx  → local file
+//	http://…, https://…          → downloaded with dl
+//	dir:fixtures[/name.xlsx]     → fixture directory
+//
+// A plain path that points at a directory is treated as a fixture directory.
+func NewSource(spec string, dl *Downloader) (ScheduleSource, error) {
+	spec = strings.TrimSpace(spec)
+
+	switch {
+	case spec == "":
+		return FileSource{Path: "sheet.xlsx"}, nil
+	case strings.HasPrefix(spec, "http://"), strings.HasPrefix(spec, "https://"):
+		return URLSource{URL: spec, Path: "sheet.xlsx", Downloader: dl}, nil
+	case strings.HasPrefix(spec, "dir:"):
+		dir := strings.TrimPrefix(spec, "dir:")
+		if strings.HasSuffix(strings.ToLower(dir), ".xlsx") {
+			return DirSource{Dir: filepath.Dir(dir), Name: filepath.Base(dir)}, nil
+		}
+		return DirSource{Dir: dir}, nil
+	case strings.HasPrefix(spec, "file:"):
+		return FileSource{Path: strings.TrimPrefix(spec, "file:")}, nil
+	}
+
+	if info, err := os.Stat(spec); err == nil && info.IsDir() {
+		return DirSource{Dir: spec}, nil
+	}
+	return FileSource{Path: spec}, nil
+}
+
+// -------------------- FILE --------------------
+
+// FileSource reads a workbook from the local filesystem.
+type FileSource struct {
+	Path string
+}
+
+func (s FileSource) Open(ctx context.Context) (io.ReadCloser, error) {
+	f, err := os.Open(s.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", s.Path, err)
+	}
+	return f, nil
+}
+
+func (s FileSource) String() string {
+	return "file:" + s.Path
+}
+
+// -------------------- URL --------------------
+
+// URLSource downloads the workbook into Path and reads it back.
+// A nil Downloader means GetExcel's defaults.
+type URLSource struct {
+	URL        string
+	Path       string
+	Downloader *Downloader
+}
+
+func (s URLSource) Open(ctx context.Context) (io.ReadCloser, error) {
+	rc, _, err := s.OpenIfChanged(ctx, FetchState{})
+	return rc, err
+}
+
+func (s URLSource) OpenIfChanged(ctx context.Context, prev FetchState) (io.ReadCloser, FetchState, error) {
+	dl := s.Downloader
+	if dl == nil {
+		dl = defaultDownloader
+	}
+	state, err := dl.Download(ctx, s.URL, s.Path, prev)
+	if err != nil {
+		return nil, state, err
+	}
+	rc, err := FileSource{Path: s.Path}.Open(ctx)
+	return rc, state, err
+}
+
+func (s URLSource) String() string {
+	return s.URL
+}
+
+// -------------------- UPLOAD --------------------
+
+// UploadSource serves a workbook that is already in memory, e.g. a multipart upload.
+type UploadSource struct {
+	Name string
+	Data []byte
+}
+
+// NewUploadSource reads r fully so the source can be opened more than once.
+func NewUploadSource(name string, r io.Reader) (UploadSource, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return UploadSource{}, fmt.Errorf("failed to read upload: %w", err)
+	}
+	return UploadSource{Name: name, Data: data}, nil
+}
+
+func (s UploadSource) Open(ctx context.Context) (io.ReadCloser, error) {
+	if len(s.Data) == 0 {
+		return nil, fmt.Errorf("upload %s is empty", s.Name)
+	}
+	return io.NopCloser(bytes.NewReader(s.Data)), nil
+}
+
+func (s UploadSource) String() string {
+	return "upload:" + s.Name
+}
+
+// bufferedSource replays bytes already read from another source, keeping
+// that source's name in reports.
+type bufferedSource struct {
+	name string
+	data []byte
+}
+
+func (s bufferedSource) Open(ctx context.Context) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(s.data)), nil
+}
+
+func (s bufferedSource) String() string {
+	return s.name
+}
+
+// -------------------- DIRECTORY --------------------
+
+// DirSource reads a workbook from a directory of fixtures. With Name set it
+// opens that file, otherwise the most recently modified *.xlsx in Dir.
+type DirSource struct {
+	Dir  string
+	Name string
+}
+
+func (s DirSource) Open(ctx context.Context) (io.ReadCloser, error) {
+	name := s.Name
+	if name == "" {
+		latest, err := s.latest()
+		if err != nil {
+			return nil, err
+		}
+		name = latest
+	}
+	return FileSource{Path: filepath.Join(s.Dir, name)}.Open(ctx)
+}
+
+func (s DirSource) latest() (string, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to read fixture dir %s: %w", s.Dir, err)
+	}
+
+	var name string
+	var newest int64
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(strings.ToLower(e.Name()), ".xlsx") || strings.HasPrefix(e.Name(), "~$") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		if mod := info.ModTime().UnixNano(); name == "" || mod > newest {
+			name, newest = e.Name(), mod
+		}
+	}
+
+	if name == "" {
+		return "", fmt.Errorf("no .xlsx files in %s", s.Dir)
+	}
+	return name, nil
+}
+
+func (s DirSource) String() string {
+	if s.Name != "" {
+		return "dir:" + filepath.Join(s.Dir, s.Name)
+	}
+	return "dir:" + s.Dir
+}
@@ -0,0 +1,122 @@
+package excel
+
+import (
+	"fmt"
+	"strings"
+)
+
+// StyleRule turns the way a cell is formatted into lesson attributes, e.g.
+// a red fill into Status "cancelled". Every condition that is set must
+// match. All matching rules apply, later ones overriding earlier ones.
+type StyleRule struct {
+	Fill      string `json:"fill" yaml:"fill"`             // background colour, "#FFC7CE"
+	FontColor string `json:"font_color" yaml:"font_color"` // text colour, "#9C0006"
+	Bold      *bool  `json:"bold" yaml:"bold"`
+	Italic    *bool  `json:"italic" yaml:"italic"`
+	Strike    *bool  `json:"strike" yaml:"strike"`
+
+	Status   string `json:"status" yaml:"status"`
+	Format   string `json:"format" yaml:"format"`
+	Building string `json:"building" yaml:"building"`
+}
+
+// styleAttributes are what the style rules say about one cell.
+type styleAttributes struct {
+	Status   string
+	Format   string
+	Building string
+}
+
+// cellLook is the part of a cell style the rules can match on.
+type cellLook struct {
+	fill, fontColor      string
+	bold, italic, strike bool
+}
+
+// normalizeColor turns "#ffc7ce", "FFC7CE" and "FFFFC7CE" (ARGB) into "FFC7CE".
+func normalizeColor(c string) string {
+	c = strings.ToUpper(strings.TrimPrefix(strings.TrimSpace(c), "#"))
+	if len(c) == 8 {
+		c = c[2:]
+	}
+	return c
+}
+
+func (r *StyleRule) compile() error {
+	r.Fill = normalizeColor(r.Fill)
+	r.FontColor = normalizeColor(r.FontColor)
+	if r.Fill == "" && r.FontColor == "" && r.Bold == nil && r.Italic == nil && r.Strike == nil {
+		return fmt.Errorf("style rule has no condition")
+	}
+	if r.Status == "" && r.Format == "" && r.Building == "" {
+		return fmt.Errorf("style rule sets no attribute")
+	}
+	return nil
+}
+
+func (r *StyleRule) matches(look cellLook) bool {
+	switch {
+	case r.Fill != "" && r.Fill != look.fill:
+		return false
+	case r.FontColor != "" && r.FontColor != look.fontColor:
+		return false
+	case r.Bold != nil && *r.Bold != look.bold:
+		return false
+	case r.Italic != nil && *r.Italic != look.italic:
+		return false
+	case r.Strike != nil && *r.Strike != look.strike:
+		return false
+	}
+	return true
+}
+
+// cellStyle applies the layout's style rules to the cell at axis. Results
+// are cached per style ID since a sheet uses only a handful of styles.
+func (sp *sheetParser) cellStyle(axis string) (styleAttributes, error) {
+	if len(sp.layout.StyleRules) == 0 {
+		return styleAttributes{}, nil
+	}
+
+	id, err := sp.f.GetCellStyle(sp.sheet, axis)
+	if err != nil {
+		return styleAttributes{}, err
+	}
+	if attrs, ok := sp.styles[id]; ok {
+		return attrs, nil
+	}
+
+	style, err := sp.f.GetStyle(id)
+	if err != nil {
+		return styleAttributes{}, err
+	}
+	var look cellLook
+	if len(style.Fill.Color) > 0 && (style.Fill.Type == "gradient" || style.Fill.Pattern != 0) {
+		look.fill = normalizeColor(style.Fill.Color[0])
+	}
+	if style.Font != nil {
+		look.fontColor = normalizeColor(style.Font.Color)
+		look.bold, look.italic, look.strike = style.Font.Bold, style.Font.Italic, style.Font.Strike
+	}
+
+	var attrs styleAttributes
+	for _, rule := range sp.layout.StyleRules {
+		if !rule.matches(look) {
+			continue
+		}
+		if rule.Status != "" {
+			attrs.Status = rule.Status
+		}
+		if rule.Format != "" {
+			attrs.Format = rule.Format
+		}
+		if rule.Building != "" {
+			attrs.Building = rule.Building
+		}
+	}
+
+	if sp.styles == nil {
+		sp.styles = make(map[int]styleAttributes)
+	}
+	sp.styles[id] = attrs
+	return attrs, nil
+}
@@ -0,0 +1,55 @@
+package excel
+
+import (
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+
+	"github.com/in-nis/cnis-back/internal/models"
+)
+
+func TestStyleRules(t *testing.T) {
+	yes := true
+	layout := testLayout(t, func(l *Layout) {
+		l.StyleRules = []StyleRule{
+			{Fill: "#ffc7ce", Status: models.LessonCancelled},
+			{Italic: &yes, Format: "online"},
+			{Fill: "FFFFC7CE", Italic: &yes, Building: "2"},
+		}
+	})
+	lessons, _ := mustParse(t, NewParser(layout), testSheet{
+		name: "12 пн",
+		cells: map[string]any{
+			"C1": "12A",
+			"B2": "08:00-08:45", "C2": "Физика\nИванов И.И.\n301",
+			"B3": "08:50-09:35", "C3": "Химия\nСеров С.С.\n305",
+			"B4": "09:45-10:30", "C4": "Англ\nSmith J.\n204",
+			"B5": "10:40-11:25", "C5": "Алгебра\nКим К.К.\n210",
+		},
+		styles: map[string]*excelize.Style{
+			"C2": {Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"FFC7CE"}}},
+			"C3": {Font: &excelize.Font{Italic: true}},
+			"C4": {
+				Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"FFC7CE"}},
+				Font: &excelize.Font{Italic: true},
+			},
+		},
+	})
+
+	tests := []struct {
+		name                     string
+		status, format, building string
+	}{
+		{"Физика", models.LessonCancelled, "", ""},
+		{"Химия", "", "online", ""},
+		{"Англ", models.LessonCancelled, "online", "2"},
+		{"Алгебра", "", "", ""},
+	}
+	for _, tt := range tests {
+		l := findLesson(t, lessons, "12A", tt.name)
+		if l.Status != tt.status || l.Format != tt.format || l.Building != tt.building {
+			t.Errorf("%s: status %q, format %q, building %q, want %q, %q, %q",
+				tt.name, l.Status, l.Format, l.Building, tt.status, tt.format, tt.building)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package excel
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/in-nis/cnis-back/internal/models"
+)
+
+// TeacherName tidies a teacher cell line: single spaces, initials written
+// together ("И. И." → "И.И.") and put after the surname, as in "Иванов И.И.".
+// Full names and lines that do not look like a name are only trimmed.
+func TeacherName(s string) string {
+	surname, initials, ok := splitName(s)
+	if !ok || fullName.MatchString(strings.TrimSpace(s)) {
+		return strings.Join(strings.Fields(s), " ")
+	}
+	if len(initials) == 0 {
+		return surname
+	}
+	return surname + " " + strings.Join(initials, ".") + "."
+}
+
+// TeacherKey identifies a teacher across spelling variants: "Иванов И.И.",
+// "Иванов И. И", "И.И. Иванов" and "Иванов Иван Иванович" share a key. Keys
+// never exceed maxKeyLen bytes.
+func TeacherKey(s string) string {
+	surname, initials, ok := splitName(s)
+	if !ok {
+		return boundKey(canonicalText(s))
+	}
+	key := strings.ToLower(strings.Join(append([]string{surname}, initials...), " "))
+	return boundKey(strings.ReplaceAll(key, "ё", "е"))
+}
+
+// splitName finds the surname and initials of a teacher line. A full name
+// ("Иванов Иван Иванович") is reduced to initials as well.
+func splitName(s string) (surname string, initials []string, ok bool) {
+	if classifyLine(s) != kindTeacher {
+		return "", nil, false
+	}
+
+	tokens := strings.FieldsFunc(s, func(r rune) bool { return unicode.IsSpace(r) || r == '.' })
+	for _, t := range tokens {
+		if surname == "" && utf8.RuneCountInString(t) > 1 {
+			surname = t
+			continue
+		}
+		r, _ := utf8.DecodeRuneInString(t)
+		initials = append(initials, string(unicode.ToUpper(r)))
+	}
+	return surname, initials, surname != ""
+}
+
+// Teachers returns one Teacher per TeacherKey of lessons, named by the
+// fullest spelling among them, as stored by db.PublishGeneration.
+func Teachers(lessons []models.Lesson) []models.Teacher {
+	index := make(map[string]int)
+	var teachers []models.Teacher
+	for _, l := range lessons {
+		if l.TeacherKey == "" {
+			continue
+		}
+		i, ok := index[l.TeacherKey]
+		if !ok {
+			index[l.TeacherKey] = len(teachers)
+			teachers = append(teachers, models.Teacher{Key: l.TeacherKey, Name: l.LessonTeacher})
+			continue
+		}
+		if len(l.LessonTeacher) > len(teachers[i].Name) {
+			teachers[i].Name = l.LessonTeacher
+		}
+	}
+	return teachers
+}
@@ -0,0 +1,77 @@
+package excel
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Period is the start and end of one lesson slot in the bell schedule.
+type Period struct {
+	Start time.Time
+	End   time.Time
+}
+
+var dashes = strings.NewReplacer("–", "-", "—", "-", "−", "-", "‐", "-", "‒", "-")
+
+var clockPattern = regexp.MustCompile(`^(\d{1,2})\s*[:.,]\s*(\d{2})(?:\s*[:.]\s*\d{2})?$`)
+
+// parseTimeRange reads a time cell. It accepts "08:00-08:45", "8.00 – 8.45",
+// "08:00 - 08:45" and Excel day fractions such as "0.3333333333". A cell
+// with a single time has hasEnd set to false and a zero end.
+func parseTimeRange(s string) (start, end time.Time, hasEnd bool, err error) {
+	s = strings.TrimSpace(dashes.Replace(s))
+	if s == "" {
+		return start, end, false, errMissingTime
+	}
+
+	parts := strings.Split(s, "-")
+	switch len(parts) {
+	case 1:
+		start, err = parseClock(parts[0])
+		return start, end, false, err
+	case 2:
+		if start, err = parseClock(parts[0]); err != nil {
+			return start, end, false, err
+		}
+		if end, err = parseClock(parts[1]); err != nil {
+			return start, end, false, err
+		}
+		if !end.After(start) {
+			return start, end, false, fmt.Errorf("time range %q ends before it starts", s)
+		}
+		return start, end, true, nil
+	}
+	return start, end, false, fmt.Errorf("cannot read time range %q", s)
+}
+
+// parseClock reads one time of day. Lesson times are dated 2000-01-01 UTC so
+// only the clock part is compared.
+func parseClock(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+
+	// Excel stores times as a fraction of a day. Anything of the form 8.45
+	// is at least 1, so fractions below 1 are never mistaken for a dotted time.
+	if f, err := strconv.ParseFloat(s, 64); err == nil && f >= 0 && f < 1 && strings.Contains(s, ".") {
+		minutes := int(math.Round(f * 24 * 60))
+		return clock(minutes/60, minutes%60)
+	}
+
+	m := clockPattern.FindStringSubmatch(s)
+	if m == nil {
+		return time.Time{}, fmt.Errorf("invalid time %q", s)
+	}
+	hour, _ := strconv.Atoi(m[1])
+	minute, _ := strconv.Atoi(m[2])
+	return clock(hour, minute)
+}
+
+func clock(hour, minute int) (time.Time, error) {
+	if hour > 23 || minute > 59 {
+		return time.Time{}, fmt.Errorf("invalid time %02d:%02d", hour, minute)
+	}
+	return time.Date(2000, 1, 1, hour, minute, 0, 0, time.UTC), nil
+}
@@ -0,0 +1,111 @@
+package excel
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimeRange(t *testing.T) {
+	tests := []struct {
+		in         string
+		start, end string // "15:04"; end is empty for a single time
+		wantErr    bool
+	}{
+		{in: "08:00-08:45", start: "08:00", end: "08:45"},
+		{in: "8:00-8:45", start: "08:00", end: "08:45"},
+		{in: "08:00 - 08:45", start: "08:00", end: "08:45"},
+		{in: "8.00 – 8.45", start: "08:00", end: "08:45"},
+		{in: "9,40—10,25", start: "09:40", end: "10:25"},
+		{in: "08:00:00-08:45:00", start: "08:00", end: "08:45"},
+		{in: "0.3333333333", start: "08:00"},
+		{in: "0.3645833333", start: "08:45"},
+		{in: "13:05", start: "13:05"},
+		{in: "", wantErr: true},
+		{in: "09:00-08:00", wantErr: true},
+		{in: "25:00-26:00", wantErr: true},
+		{in: "8-9-10", wantErr: true},
+		{in: "обед", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		start, end, hasEnd, err := parseTimeRange(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseTimeRange(%q) = %s-%s, want an error", tt.in, start.Format("15:04"), end.Format("15:04"))
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseTimeRange(%q): %v", tt.in, err)
+			continue
+		}
+		if got := start.Format("15:04"); got != tt.start {
+			t.Errorf("parseTimeRange(%q) start = %s, want %s", tt.in, got, tt.start)
+		}
+		if hasEnd != (tt.end != "") {
+			t.Errorf("parseTimeRange(%q) hasEnd = %v, want %v", tt.in, hasEnd, tt.end != "")
+		} else if hasEnd && end.Format("15:04") != tt.end {
+			t.Errorf("parseTimeRange(%q) end = %s, want %s", tt.in, end.Format("15:04"), tt.end)
+		}
+		if start.Year() != 2000 || start.Location() != time.UTC {
+			t.Errorf("parseTimeRange(%q) start %v is not dated 2000-01-01 UTC", tt.in, start)
+		}
+	}
+}
+
+func TestParseClock(t *testing.T) {
+	tests := map[string]string{
+		"8:05":         "08:05",
+		"08.05":        "08:05",
+		"8,05":         "08:05",
+		"0.5":          "12:00",
+		"0.7291666667": "17:30",
+	}
+	for in, want := range tests {
+		got, err := parseClock(in)
+		if err != nil {
+			t.Errorf("parseClock(%q): %v", in, err)
+			continue
+		}
+		if got.Format("15:04") != want {
+			t.Errorf("parseClock(%q) = %s, want %s", in, got.Format("15:04"), want)
+		}
+	}
+
+	for _, in := range []string{"1", "8", "12:60", "ten"} {
+		if _, err := parseClock(in); err == nil {
+			t.Errorf("parseClock(%q) succeeded, want an error", in)
+		}
+	}
+}
+
+func TestBellScheduleFallback(t *testing.T) {
+	layout := testLayout(t, func(l *Layout) {
+		l.AutoDetect = false
+		l.PeriodColumn = "A"
+		l.DaySource = DayFromSheetName
+		l.BellSchedule = map[int]string{1: "08:00-08:45", 2: "08:50-09:35", 3: "09:45-10:30"}
+	})
+
+	lessons, _ := mustParse(t, NewParser(layout), testSheet{
+		name: "12 Понедельник",
+		cells: map[string]any{
+			"C1": "12A",
+			"A2": 1, "B2": "", "C2": "Физика\nИванов И.И.\n301", // no time: whole bell
+			"A3": 2, "B3": "08:50", "C3": "Химия\nПетров П.П.\n302", // start only: bell end
+			"A4": 3, "B4": "09:40-10:20", "C4": "Биология\nСидорова А.А.\n303", // own range wins
+		},
+	})
+
+	tests := []struct{ name, start, end string }{
+		{"Физика", "08:00", "08:45"},
+		{"Химия", "08:50", "09:35"},
+		{"Биология", "09:40", "10:20"},
+	}
+	for _, tt := range tests {
+		l := findLesson(t, lessons, "12A", tt.name)
+		if got := l.LessonStart.Format("15:04") + "-" + l.LessonEnd.Format("15:04"); got != tt.start+"-"+tt.end {
+			t.Errorf("%s at %s, want %s-%s", tt.name, got, tt.start, tt.end)
+		}
+	}
+}
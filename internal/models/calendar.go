@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Term is a teaching period of the academic year. Both dates are inclusive.
+type Term struct {
+    ID        uint      `gorm:"primaryKey"`
+    Name      string    // e.g. "1 четверть"
+    StartDate time.Time `gorm:"type:date;not null"`
+    EndDate   time.Time `gorm:"type:date;not null"`
+}
+
+// Calendar day kinds
+const (
+    CalendarHoliday   = "holiday"    // public holiday, no lessons
+    CalendarNonSchool = "non_school" // no lessons for another reason, e.g. a staff day
+    CalendarSwap      = "swap"       // the timetable of AsDay runs instead of the usual one
+)
+
+// CalendarDay is an exception to the weekly timetable on one date.
+type CalendarDay struct {
+    ID    uint      `gorm:"primaryKey"`
+    Date  time.Time `gorm:"type:date;uniqueIndex;not null"`
+    Kind  string    `gorm:"size:16;not null"`
+    AsDay int       // for swaps: weekday whose lessons run, 1=Mon, 7=Sun
+    Name  string    // e.g. "Наурыз"
+}
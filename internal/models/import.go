@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// ImportReport keeps the parser's report for one import attempt.
+// Report holds the excel.ParseReport as JSON.
+type ImportReport struct {
+    ID           uint      `gorm:"primaryKey"`
+    CreatedAt    time.Time `gorm:"index"`
+    Source       string
+    GenerationID *uint     // nil when the import was not published
+    Lessons      int
+    Warnings     int
+    Errors       int
+    Report       []byte    `gorm:"type:jsonb"`
+}
+
+// ParallelSetting lets an admin switch a grade parallel off for imports.
+// Parallels without a row are enabled.
+type ParallelSetting struct {
+    Grade     int       `gorm:"primaryKey;autoIncrement:false"`
+    Enabled   bool      `gorm:"not null"`
+    UpdatedAt time.Time
+}
+
+// Import log statuses
+const (
+    ImportImported  = "imported"
+    ImportUnchanged = "unchanged"
+    ImportFailed    = "failed"
+)
+
+// ImportLog records every check of the schedule source, including the ones
+// that found nothing new.
+type ImportLog struct {
+    ID           uint      `gorm:"primaryKey"`
+    CreatedAt    time.Time `gorm:"index"`
+    Source       string    `gorm:"index"`
+    Status       string    `gorm:"not null"`
+    ContentHash  string
+    ETag         string
+    LastModified string
+    Fingerprint  string    // excel.Parser.Fingerprint of the settings used
+    GenerationID *uint
+    Message      string
+}
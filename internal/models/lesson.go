@@ -4,15 +4,59 @@ import "time"
 
 type Lesson struct {
     ID           uint      `gorm:"primaryKey"`
+    GenerationID uint      `gorm:"index"` // ScheduleGeneration this lesson belongs to
+    LessonKey    string    `gorm:"size:40;index"` // stable natural key, see excel.LessonKey
     Grade        int       `gorm:"not null"`
     GradeLetter  string    `gorm:"size:1"`
     LessonDay    int       `gorm:"not null"` // 1=Mon, 7=Sun
+    Period       int       // number of the first period the lesson covers
+    PeriodEnd    int       // number of the last period; equals Period unless it is a double
     LessonStart time.Time `gorm:"type:time"`
 	LessonEnd   time.Time `gorm:"type:time"`
     LessonName   string    `gorm:"not null"`
     LessonTeacher string
+    TeacherKey    string    `gorm:"size:128;index"` // see excel.TeacherKey; empty when no teacher
     LessonClass   string
+    RoomKey       string    `gorm:"size:128;index"` // see excel.RoomKey; empty when no room
     LessonGroup   string
+    StreamID      string    `gorm:"index"` // shared by lessons merged across several classes
+    Status        string    `gorm:"size:32"` // from the cell's style, e.g. LessonCancelled; empty means as planned
+    Format        string    `gorm:"size:32"` // from the cell's style, e.g. "online"
+    Building      string    `gorm:"size:64"` // from the cell's style, e.g. "2"
+}
+
+// LessonCancelled is the Status of a lesson that does not take place.
+const LessonCancelled = "cancelled"
+
+// ScheduleGeneration is one imported snapshot of the timetable.
+// Exactly one generation is active at a time; older ones are kept for rollback.
+type ScheduleGeneration struct {
+    ID          uint      `gorm:"primaryKey"`
+    Source      string
+    LessonCount int
+    Active      bool      `gorm:"index;uniqueIndex:idx_schedule_generations_one_active,where:active"` // at most one row is true
+    CreatedAt   time.Time
+    PublishedAt *time.Time
+}
+
+// Teacher is a teacher found in the live or an earlier imported schedule.
+// Lessons link to it through TeacherKey.
+type Teacher struct {
+    ID        uint      `gorm:"primaryKey"`
+    Key       string    `gorm:"size:128;uniqueIndex;not null"` // see excel.TeacherKey
+    Name      string    `gorm:"not null"` // fullest spelling seen, e.g. "Иванов Иван Иванович"
+    UpdatedAt time.Time
+}
+
+// Room is a classroom found in the live or an earlier imported schedule.
+// Lessons link to it through RoomKey.
+type Room struct {
+    ID        uint      `gorm:"primaryKey"`
+    Key       string    `gorm:"size:128;uniqueIndex;not null"` // see excel.RoomKey
+    Number    string    `gorm:"not null"` // e.g. "301", "Б-204", "Спортзал"
+    Building  string    `gorm:"size:64"`
+    Floor     *int      // guessed from the number; nil for named rooms
+    UpdatedAt time.Time
 }
 
 type User struct {